@@ -0,0 +1,161 @@
+package glog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// adminStatus is the JSON document served by AdminHandler.
+type adminStatus struct {
+	Level          string     `json:"level"`
+	ElevatedUntil  *time.Time `json:"elevated_until,omitempty"`
+	RestoreLevel   string     `json:"restore_level,omitempty"`
+	DroppedEntries uint64     `json:"dropped_entries,omitempty"`
+	Config         *Config    `json:"config"`
+}
+
+// adminLevelRequest is the JSON body accepted by AdminHandler on PUT.
+type adminLevelRequest struct {
+	Level string `json:"level"`
+	// TTL, if set, elevates the level temporarily (see ElevateLevel), e.g. "10m".
+	TTL string `json:"ttl"`
+}
+
+// AdminHandler returns an http.Handler to inspect and adjust the global logger
+// at runtime. Mount it on an admin port, or on a gin router with
+// ginmw.AdminHandler.
+//
+//   - GET returns the current level, the entries dropped by an async queue
+//     and the effective config as JSON, with the header values and URL
+//     credentials of outputs replaced by REDACTED.
+//   - PUT changes the level, given as a JSON body {"level":"debug"}, a
+//     "level" query/form value or a plain-text body, and returns the new status.
+//     With a "ttl" ({"level":"debug","ttl":"10m"} or ?ttl=10m) the level is
+//     only elevated for that duration, see ElevateLevel.
+//   - POST flushes buffered log entries (see Flush).
+//
+// 示例：
+//
+//	http.Handle("/debug/log", glog.AdminHandler())
+//
+//	curl -X PUT -d '{"level":"debug"}' http://127.0.0.1:6060/debug/log
+func AdminHandler() http.Handler {
+	return http.HandlerFunc(serveAdmin)
+}
+
+func serveAdmin(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		writeAdminJSON(w, http.StatusOK, currentAdminStatus())
+	case http.MethodPut:
+		req, err := adminRequestedLevel(r)
+		if err != nil {
+			writeAdminError(w, http.StatusBadRequest, err)
+			return
+		}
+		level, err := parseLevel(req.Level)
+		if err != nil {
+			writeAdminError(w, http.StatusBadRequest, err)
+			return
+		}
+		if req.TTL == "" {
+			SetLevel(level)
+		} else {
+			ttl, err := time.ParseDuration(req.TTL)
+			if err != nil || ttl <= 0 {
+				writeAdminError(w, http.StatusBadRequest, fmt.Errorf("invalid ttl %q: want a positive duration such as 10m", req.TTL))
+				return
+			}
+			ElevateLevel(level, ttl)
+		}
+		writeAdminJSON(w, http.StatusOK, currentAdminStatus())
+	case http.MethodPost:
+		if err := Flush(); err != nil {
+			writeAdminError(w, http.StatusInternalServerError, fmt.Errorf("flush failed: %w", err))
+			return
+		}
+		writeAdminJSON(w, http.StatusOK, map[string]bool{"flushed": true})
+	default:
+		w.Header().Set("Allow", "GET, PUT, POST")
+		writeAdminError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+	}
+}
+
+// adminRequestedLevel reads the level and optional ttl from a JSON body, the
+// query or form values, or a plain-text body, in that order.
+func adminRequestedLevel(r *http.Request) (adminLevelRequest, error) {
+	var req adminLevelRequest
+	body, err := io.ReadAll(io.LimitReader(r.Body, 64<<10))
+	if err != nil {
+		return req, fmt.Errorf("failed to read body: %w", err)
+	}
+
+	if err := json.Unmarshal(body, &req); err == nil && req.Level != "" {
+		return req, nil
+	}
+	query := r.URL.Query()
+	if level := query.Get("level"); level != "" {
+		return adminLevelRequest{Level: level, TTL: query.Get("ttl")}, nil
+	}
+	if values, err := url.ParseQuery(string(body)); err == nil && values.Get("level") != "" {
+		return adminLevelRequest{Level: values.Get("level"), TTL: values.Get("ttl")}, nil
+	}
+	if text := strings.TrimSpace(string(body)); text != "" && !strings.ContainsAny(text, "{=") {
+		return adminLevelRequest{Level: text, TTL: query.Get("ttl")}, nil
+	}
+	return req, fmt.Errorf("missing level")
+}
+
+func currentAdminStatus() adminStatus {
+	status := adminStatus{Level: GetLevel().String()}
+	if active, restoreTo, until := elevationStatus(); active {
+		status.ElevatedUntil = &until
+		status.RestoreLevel = restoreTo.String()
+	}
+	status.DroppedEntries = DroppedEntries()
+	status.Config = redactConfig(CurrentConfig())
+	return status
+}
+
+// adminRedacted replaces the secrets of the config served by AdminHandler.
+const adminRedacted = "REDACTED"
+
+// redactConfig removes the secrets from cfg, a copy, before it is served:
+// the header values of outputs, which often carry tokens, and the
+// credentials of their URLs.
+func redactConfig(cfg *Config) *Config {
+	if cfg == nil {
+		return nil
+	}
+	for i := range cfg.Outputs {
+		out := &cfg.Outputs[i]
+		for name := range out.Headers {
+			out.Headers[name] = adminRedacted
+		}
+		if out.URL == "" {
+			continue
+		}
+		if u, err := url.Parse(out.URL); err != nil {
+			out.URL = adminRedacted
+		} else if u.User != nil {
+			u.User = url.User(adminRedacted)
+			out.URL = u.String()
+		}
+	}
+	return cfg
+}
+
+func writeAdminJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v) //nolint:errcheck
+}
+
+func writeAdminError(w http.ResponseWriter, code int, err error) {
+	writeAdminJSON(w, code, map[string]string{"error": err.Error()})
+}
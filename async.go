@@ -0,0 +1,243 @@
+package glog
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// Policies of AsyncConfig when the queue is full.
+const (
+	// AsyncBlock makes the logging goroutine wait for a free slot.
+	AsyncBlock = "block"
+	// AsyncDropNewest discards the entry being logged.
+	AsyncDropNewest = "drop_newest"
+	// AsyncDropOldest discards the oldest queued entry to make room.
+	AsyncDropOldest = "drop_oldest"
+	// AsyncDropBelowLevel discards entries below AsyncConfig.DropLevel and
+	// blocks for the others.
+	AsyncDropBelowLevel = "drop_below_level"
+)
+
+// defaultAsyncQueueSize is the queue size used when AsyncConfig.QueueSize is 0.
+const defaultAsyncQueueSize = 4096
+
+// AsyncConfig moves writing to a background goroutine, so logging calls do
+// not wait for disk or network I/O. Entries are queued in a bounded queue;
+// Policy decides what happens when it is full. Flush and Sync wait until
+// every entry logged before them has been written.
+//
+// Entries are encoded by the logging goroutine and queued once per file or
+// output they go to. Outputs whose sink implements EntrySink are handed the
+// entry directly, as its fields are only valid during the call.
+type AsyncConfig struct {
+	Enabled bool `yaml:"enabled" json:"enabled" toml:"enabled"`
+	// QueueSize is the maximum number of queued entries, 4096 by default.
+	QueueSize int `yaml:"queue_size" json:"queue_size" toml:"queue_size"`
+	// Policy is block (default), drop_newest, drop_oldest or drop_below_level.
+	Policy string `yaml:"policy" json:"policy" toml:"policy"`
+	// DropLevel is the level below which drop_below_level discards entries,
+	// warn by default.
+	DropLevel string `yaml:"drop_level" json:"drop_level" toml:"drop_level"`
+}
+
+// validate returns the problems of c, prefixed with key.
+func (c AsyncConfig) validate(key string) []string {
+	var problems []string
+	if c.QueueSize < 0 {
+		problems = append(problems, fmt.Sprintf("%s.queue_size: must not be negative, got %d", key, c.QueueSize))
+	}
+	switch c.Policy {
+	case "", AsyncBlock, AsyncDropNewest, AsyncDropOldest, AsyncDropBelowLevel:
+	default:
+		problems = append(problems, fmt.Sprintf("%s.policy: unknown policy %q (want %s, %s, %s or %s)",
+			key, c.Policy, AsyncBlock, AsyncDropNewest, AsyncDropOldest, AsyncDropBelowLevel))
+	}
+	if c.DropLevel != "" {
+		if _, err := parseLevel(c.DropLevel); err != nil {
+			problems = append(problems, fmt.Sprintf("%s.drop_level: %v", key, err))
+		}
+	}
+	return problems
+}
+
+// DroppedEntries returns the number of entries the global logger discarded
+// because its async queue was full, see AsyncConfig.
+func DroppedEntries() uint64 {
+	if s := getState(); s != nil {
+		return s.async.Dropped()
+	}
+	return 0
+}
+
+// DroppedEntries returns the number of entries the logger discarded because
+// its async queue was full, see AsyncConfig.
+func (l *Logger) DroppedEntries() uint64 {
+	return l.async.Dropped()
+}
+
+// asyncItem is an encoded entry together with the destination it goes to.
+type asyncItem struct {
+	seq   uint64
+	level zapcore.Level
+	out   zapcore.WriteSyncer
+	buf   *buffer.Buffer
+}
+
+// write writes the entry and releases its buffer. Like zap's own write
+// errors without an ErrorOutput, failures are dropped.
+func (it *asyncItem) write() {
+	it.out.Write(it.buf.Bytes()) //nolint:errcheck
+	it.buf.Free()
+}
+
+// asyncQueue is a bounded FIFO of entries written by a single background
+// goroutine. Entries are numbered so flush can wait for exactly the entries
+// queued before it, even while other goroutines keep logging.
+type asyncQueue struct {
+	policy    string
+	dropLevel zapcore.Level
+	dropped   atomic.Uint64
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	items   []asyncItem // ring buffer
+	start   int
+	count   int
+	next    uint64 // sequence number of the next queued entry
+	writing uint64 // sequence number of the entry being written, 0 if none
+	closed  bool
+	done    chan struct{}
+}
+
+func newAsyncQueue(cfg AsyncConfig) *asyncQueue {
+	size := cfg.QueueSize
+	if size <= 0 {
+		size = defaultAsyncQueueSize
+	}
+	policy := cfg.Policy
+	if policy == "" {
+		policy = AsyncBlock
+	}
+	dropLevel := zapcore.WarnLevel
+	if level, err := parseLevel(cfg.DropLevel); cfg.DropLevel != "" && err == nil {
+		dropLevel = level
+	}
+
+	q := &asyncQueue{
+		policy:    policy,
+		dropLevel: dropLevel,
+		items:     make([]asyncItem, size),
+		next:      1,
+		done:      make(chan struct{}),
+	}
+	q.cond = sync.NewCond(&q.mu)
+	go q.run()
+	return q
+}
+
+// push queues it according to the policy. It returns false once the queue
+// is closed, so the caller can write it synchronously instead.
+func (q *asyncQueue) push(it asyncItem) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for q.count == len(q.items) && !q.closed {
+		switch {
+		case q.policy == AsyncDropNewest,
+			q.policy == AsyncDropBelowLevel && it.level < q.dropLevel:
+			it.buf.Free()
+			q.dropped.Add(1)
+			return true
+		case q.policy == AsyncDropOldest:
+			q.pop().buf.Free()
+			q.dropped.Add(1)
+		default:
+			q.cond.Wait()
+		}
+	}
+	if q.closed {
+		return false
+	}
+
+	it.seq = q.next
+	q.next++
+	q.items[(q.start+q.count)%len(q.items)] = it
+	q.count++
+	q.cond.Broadcast()
+	return true
+}
+
+// pop removes the oldest entry. q.mu must be held and q.count > 0.
+func (q *asyncQueue) pop() asyncItem {
+	it := q.items[q.start]
+	q.items[q.start] = asyncItem{}
+	q.start = (q.start + 1) % len(q.items)
+	q.count--
+	return it
+}
+
+// run writes queued entries until the queue is closed and empty.
+func (q *asyncQueue) run() {
+	defer close(q.done)
+
+	q.mu.Lock()
+	for {
+		for q.count == 0 && !q.closed {
+			q.cond.Wait()
+		}
+		if q.count == 0 {
+			q.mu.Unlock()
+			return
+		}
+		it := q.pop()
+		q.writing = it.seq
+		q.cond.Broadcast()
+		q.mu.Unlock()
+
+		it.write()
+
+		q.mu.Lock()
+		q.writing = 0
+		q.cond.Broadcast()
+	}
+}
+
+// flush waits until every entry queued before the call has been written
+// or dropped.
+func (q *asyncQueue) flush() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	last := q.next - 1
+	for !q.closed {
+		pending := q.count > 0 && q.items[q.start].seq <= last
+		if !pending && (q.writing == 0 || q.writing > last) {
+			return
+		}
+		q.cond.Wait()
+	}
+}
+
+// Close writes the remaining entries and stops the background goroutine.
+// Entries logged afterwards are written synchronously.
+func (q *asyncQueue) Close() error {
+	q.mu.Lock()
+	q.closed = true
+	q.cond.Broadcast()
+	q.mu.Unlock()
+
+	<-q.done
+	return nil
+}
+
+// Dropped returns the number of discarded entries; q may be nil.
+func (q *asyncQueue) Dropped() uint64 {
+	if q == nil {
+		return 0
+	}
+	return q.dropped.Load()
+}
@@ -0,0 +1,248 @@
+package glog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// gatedWriter blocks every write until open is closed and reports on
+// started when the first write begins. It is a plain WriteSyncer, as entry
+// sinks are not queued.
+type gatedWriter struct {
+	mem     memorySink
+	started chan struct{}
+	open    chan struct{}
+	once    sync.Once
+}
+
+func newGatedWriter() *gatedWriter {
+	return &gatedWriter{started: make(chan struct{}), open: make(chan struct{})}
+}
+
+func (w *gatedWriter) Write(p []byte) (int, error) {
+	w.once.Do(func() { close(w.started) })
+	<-w.open
+	return w.mem.Write(p)
+}
+
+func (w *gatedWriter) Sync() error { return nil }
+
+func (w *gatedWriter) String() string { return w.mem.String() }
+
+// newGatedLogger returns an async logger whose background goroutine is
+// stuck writing the entry "first", so the queue fills up deterministically.
+func newGatedLogger(t *testing.T, cfg AsyncConfig) (*zap.Logger, *asyncQueue, *gatedWriter) {
+	t.Helper()
+	w := newGatedWriter()
+	enc := zapcore.NewConsoleEncoder(zapcore.EncoderConfig{MessageKey: "msg", LineEnding: "\n"})
+	queue := newAsyncQueue(cfg)
+	logger := zap.New(newCore(enc, w, zap.DebugLevel, queue))
+	t.Cleanup(func() { queue.Close() })
+
+	logger.Info("first")
+	select {
+	case <-w.started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the background writer")
+	}
+	return logger, queue, w
+}
+
+func TestAsyncPolicies(t *testing.T) {
+	tests := []struct {
+		policy  string
+		log     func(l *zap.Logger)
+		want    string
+		dropped uint64
+	}{
+		{
+			policy: AsyncDropNewest,
+			log: func(l *zap.Logger) {
+				for i := 1; i <= 4; i++ {
+					l.Info(fmt.Sprint("entry", i))
+				}
+			},
+			want:    "first\nentry1\nentry2\n",
+			dropped: 2,
+		},
+		{
+			policy: AsyncDropOldest,
+			log: func(l *zap.Logger) {
+				for i := 1; i <= 4; i++ {
+					l.Info(fmt.Sprint("entry", i))
+				}
+			},
+			want:    "first\nentry3\nentry4\n",
+			dropped: 2,
+		},
+		{
+			policy: AsyncDropBelowLevel,
+			log: func(l *zap.Logger) {
+				l.Info("entry1")
+				l.Info("entry2")
+				l.Info("entry3")
+				l.Debug("entry4")
+			},
+			want:    "first\nentry1\nentry2\n",
+			dropped: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			logger, queue, w := newGatedLogger(t, AsyncConfig{QueueSize: 2, Policy: tt.policy})
+			tt.log(logger)
+			if queue.Dropped() != tt.dropped {
+				t.Errorf("Expected %d dropped entries, got %d", tt.dropped, queue.Dropped())
+			}
+
+			close(w.open)
+			logger.Sync() //nolint:errcheck
+			if got := w.String(); got != tt.want {
+				t.Errorf("Unexpected output.\nGot:  %q\nWant: %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAsyncDropBelowLevelBlocksForHigherLevels(t *testing.T) {
+	logger, queue, w := newGatedLogger(t, AsyncConfig{QueueSize: 1, Policy: AsyncDropBelowLevel, DropLevel: "error"})
+	logger.Warn("queued")
+	logger.Warn("dropped")
+
+	done := make(chan struct{})
+	go func() {
+		logger.Error("blocked")
+		close(done)
+	}()
+	select {
+	case <-done:
+		t.Fatal("An error entry should wait for a free slot")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(w.open)
+	<-done
+	logger.Sync() //nolint:errcheck
+	if got, want := w.String(), "first\nqueued\nblocked\n"; got != want {
+		t.Errorf("Unexpected output.\nGot:  %q\nWant: %q", got, want)
+	}
+	if queue.Dropped() != 1 {
+		t.Errorf("Expected 1 dropped entry, got %d", queue.Dropped())
+	}
+}
+
+func TestAsyncEncodesOnLoggingGoroutine(t *testing.T) {
+	logger, _, w := newGatedLogger(t, AsyncConfig{QueueSize: 4})
+	fields := map[string]int{"n": 1}
+	logger.Info("queued", zap.Any("fields", fields))
+	fields["n"] = 2
+
+	close(w.open)
+	logger.Sync() //nolint:errcheck
+	if got := w.String(); !strings.Contains(got, `queued	{"fields": {"n":1}}`) {
+		t.Errorf("The entry should be encoded when it is logged. Got: %q", got)
+	}
+}
+
+func TestAsyncWritesEntriesQueuedBeforeLevelChange(t *testing.T) {
+	w := newGatedWriter()
+	enc := zapcore.NewConsoleEncoder(zapcore.EncoderConfig{MessageKey: "msg", LineEnding: "\n"})
+	level := zap.NewAtomicLevelAt(zap.InfoLevel)
+	queue := newAsyncQueue(AsyncConfig{QueueSize: 4})
+	t.Cleanup(func() { queue.Close() })
+	logger := zap.New(newCore(enc, w, level, queue))
+
+	logger.Info("first")
+	<-w.started
+	logger.Info("queued")
+	level.SetLevel(zap.ErrorLevel)
+	logger.Info("filtered")
+
+	close(w.open)
+	logger.Sync() //nolint:errcheck
+	if got, want := w.String(), "first\nqueued\n"; got != want {
+		t.Errorf("Unexpected output.\nGot:  %q\nWant: %q", got, want)
+	}
+}
+
+func TestAsyncFlushDrainsQueue(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "glog_test_async")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	if err := InitWithConfig(&Config{Directory: tempDir, Async: AsyncConfig{Enabled: true, QueueSize: 8}}); err != nil {
+		t.Fatalf("InitWithConfig failed: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				Infof("async_entry_%d_%d", g, i)
+			}
+		}(g)
+	}
+	wg.Wait()
+	if err := Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(tempDir, "app.log"))
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	if n := strings.Count(string(content), "async_entry_"); n != 400 {
+		t.Errorf("Expected 400 entries after Flush with the block policy, got %d", n)
+	}
+	if DroppedEntries() != 0 {
+		t.Errorf("The block policy should not drop entries, got %d", DroppedEntries())
+	}
+}
+
+func TestAsyncCloseWritesRemainingEntries(t *testing.T) {
+	logger, queue, w := newGatedLogger(t, AsyncConfig{QueueSize: 4})
+	logger.Info("queued")
+
+	closed := make(chan struct{})
+	go func() {
+		queue.Close()
+		close(closed)
+	}()
+	close(w.open)
+	<-closed
+
+	// After Close entries are written synchronously.
+	logger.Info("after_close")
+	if got, want := w.String(), "first\nqueued\nafter_close\n"; got != want {
+		t.Errorf("Unexpected output.\nGot:  %q\nWant: %q", got, want)
+	}
+}
+
+func TestAsyncValidate(t *testing.T) {
+	cfg := &Config{
+		Directory: os.TempDir(),
+		Async:     AsyncConfig{QueueSize: -1, Policy: "drop_random", DropLevel: "loud"},
+	}
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("Expected an error for an invalid async config, got nil")
+	}
+	for _, want := range []string{"async.queue_size", "async.policy", "async.drop_level"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Validation error should mention %q, got: %v", want, err)
+		}
+	}
+}
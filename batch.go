@@ -0,0 +1,336 @@
+package glog
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// batchItem is an entry waiting to be sent by a batching sink.
+type batchItem struct {
+	ent  zapcore.Entry
+	data []byte
+	// extra holds what a sink derived from the fields while the entry was
+	// logged, e.g. the stream labels of a loki entry. Fields themselves are
+	// not kept, as they may refer to values the caller changes later.
+	extra interface{}
+}
+
+// batchConfig bounds the batches of a batching sink.
+type batchConfig struct {
+	// size, bytes and delay end a batch: after size entries, once it holds
+	// bytes of encoded entries, or delay after its first entry.
+	size  int
+	bytes int
+	delay time.Duration
+	// maxPending is the number of batches waiting to be sent; the oldest
+	// is dropped when a new one would exceed it.
+	maxPending int
+}
+
+// batchOptions reads the batch options shared by the http, loki and otlp
+// outputs: batch_size, batch_bytes, batch_delay and max_pending.
+func batchOptions(opts *outputOptions) batchConfig {
+	return batchConfig{
+		size:       opts.intOpt("batch_size", 100),
+		bytes:      opts.intOpt("batch_bytes", 1<<20),
+		delay:      opts.durationOpt("batch_delay", time.Second),
+		maxPending: opts.intOpt("max_pending", 16),
+	}
+}
+
+// queuedBatch is a batch handed over to the sending goroutine.
+type queuedBatch struct {
+	seq   uint64
+	items []batchItem
+}
+
+// batcher collects items into batches and sends them from a background
+// goroutine, so adding an item never waits for the network. Like
+// asyncQueue, batches are numbered so flush waits for exactly those that
+// were started before it.
+type batcher struct {
+	cfg     batchConfig
+	send    func([]batchItem) error
+	onError func(error)
+	dropped atomic.Uint64
+
+	mu       sync.Mutex
+	cond     *sync.Cond
+	current  []batchItem
+	size     int    // bytes in current
+	timerGen uint64 // identifies the timer of current
+	timer    *time.Timer
+	queue    []queuedBatch
+	next     uint64 // sequence number of the next queued batch
+	sending  uint64 // sequence number of the batch being sent, 0 if none
+	err      error  // the last send error since the previous flush
+	closed   bool
+	done     chan struct{}
+}
+
+func newBatcher(cfg batchConfig, send func([]batchItem) error, onError func(error)) *batcher {
+	b := &batcher{cfg: cfg, send: send, onError: onError, next: 1, done: make(chan struct{})}
+	b.cond = sync.NewCond(&b.mu)
+	go b.run()
+	return b
+}
+
+// add appends it to the current batch, which is queued once it is full.
+func (b *batcher) add(it batchItem) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return errSinkClosed
+	}
+
+	b.current = append(b.current, it)
+	b.size += len(it.data)
+	if len(b.current) >= b.cfg.size || b.size >= b.cfg.bytes {
+		b.handoff()
+	} else if len(b.current) == 1 {
+		gen := b.timerGen
+		b.timer = time.AfterFunc(b.cfg.delay, func() {
+			b.mu.Lock()
+			if b.timerGen == gen {
+				b.handoff()
+			}
+			b.mu.Unlock()
+		})
+	}
+	return nil
+}
+
+// handoff queues the current batch for sending. b.mu must be held.
+func (b *batcher) handoff() {
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	b.timerGen++
+	if len(b.current) == 0 {
+		return
+	}
+
+	b.queue = append(b.queue, queuedBatch{seq: b.next, items: b.current})
+	b.next++
+	b.current, b.size = nil, 0
+	if len(b.queue) > b.cfg.maxPending {
+		b.dropped.Add(uint64(len(b.queue[0].items)))
+		b.queue[0] = queuedBatch{}
+		b.queue = b.queue[1:]
+	}
+	b.cond.Broadcast()
+}
+
+// run sends queued batches until the batcher is closed and drained.
+func (b *batcher) run() {
+	defer close(b.done)
+
+	b.mu.Lock()
+	for {
+		for len(b.queue) == 0 && !b.closed {
+			b.cond.Wait()
+		}
+		if len(b.queue) == 0 {
+			b.mu.Unlock()
+			return
+		}
+		batch := b.queue[0]
+		b.queue[0] = queuedBatch{}
+		b.queue = b.queue[1:]
+		b.sending = batch.seq
+		b.mu.Unlock()
+
+		err := b.send(batch.items)
+		if err != nil {
+			b.dropped.Add(uint64(len(batch.items)))
+			b.onError(err)
+		}
+
+		b.mu.Lock()
+		if err != nil {
+			b.err = err
+		}
+		b.sending = 0
+		b.cond.Broadcast()
+	}
+}
+
+// flush sends the current batch and waits until it and every batch queued
+// before it has been sent or has failed. It returns the last send error
+// since the previous flush.
+func (b *batcher) flush() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.handoff()
+	last := b.next - 1
+	for {
+		pending := len(b.queue) > 0 && b.queue[0].seq <= last
+		if !pending && (b.sending == 0 || b.sending > last) {
+			break
+		}
+		b.cond.Wait()
+	}
+	err := b.err
+	b.err = nil
+	return err
+}
+
+// close sends what is left and stops the background goroutine.
+func (b *batcher) close() error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.handoff()
+	b.closed = true
+	b.cond.Broadcast()
+	b.mu.Unlock()
+
+	<-b.done
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.err
+}
+
+// httpPoster posts request bodies, retrying with exponential backoff on
+// network errors, 5xx and 429 responses.
+type httpPoster struct {
+	url        string
+	headers    map[string]string
+	gzip       bool
+	client     *http.Client
+	maxRetries int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+}
+
+// newHTTPPoster reads the options shared by the http, loki and otlp
+// outputs: gzip, timeout, max_retries, min_backoff and max_backoff.
+func newHTTPPoster(out OutputConfig, opts *outputOptions) *httpPoster {
+	return &httpPoster{
+		url:        out.URL,
+		headers:    out.Headers,
+		gzip:       opts.boolOpt("gzip", false),
+		client:     &http.Client{Timeout: opts.durationOpt("timeout", 10*time.Second)},
+		maxRetries: opts.intOpt("max_retries", 3),
+		minBackoff: opts.durationOpt("min_backoff", 100*time.Millisecond),
+		maxBackoff: opts.durationOpt("max_backoff", 10*time.Second),
+	}
+}
+
+// httpStatusError is a response with an unexpected status.
+type httpStatusError struct {
+	url        string
+	status     string
+	code       int
+	body       string
+	retryAfter time.Duration
+}
+
+func (e *httpStatusError) Error() string {
+	msg := fmt.Sprintf("unexpected status %s from %s", e.status, e.url)
+	if e.body != "" {
+		msg += ": " + e.body
+	}
+	return msg
+}
+
+func (e *httpStatusError) retryable() bool {
+	return e.code == http.StatusTooManyRequests || e.code >= 500
+}
+
+// post sends body, retrying up to maxRetries times.
+func (p *httpPoster) post(contentType string, body []byte) error {
+	encoding := ""
+	if p.gzip {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		zw.Write(body) //nolint:errcheck
+		if err := zw.Close(); err != nil {
+			return err
+		}
+		body, encoding = buf.Bytes(), "gzip"
+	}
+
+	backoff := p.minBackoff
+	for attempt := 0; ; attempt++ {
+		err := p.do(contentType, encoding, body)
+		if err == nil {
+			return nil
+		}
+		wait := backoff
+		if statusErr, ok := err.(*httpStatusError); ok {
+			if !statusErr.retryable() {
+				return err
+			}
+			if statusErr.retryAfter > 0 {
+				wait = min(statusErr.retryAfter, p.maxBackoff)
+			}
+		}
+		if attempt >= p.maxRetries {
+			return fmt.Errorf("giving up after %d attempts: %w", attempt+1, err)
+		}
+		time.Sleep(wait)
+		backoff = min(backoff*2, p.maxBackoff)
+	}
+}
+
+func (p *httpPoster) do(contentType, encoding string, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	if encoding != "" {
+		req.Header.Set("Content-Encoding", encoding)
+	}
+	for key, value := range p.headers {
+		if http.CanonicalHeaderKey(key) == "Host" {
+			req.Host = value
+			continue
+		}
+		req.Header.Set(key, value)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	io.Copy(io.Discard, resp.Body) //nolint:errcheck
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	statusErr := &httpStatusError{url: p.url, status: resp.Status, code: resp.StatusCode, body: string(bytes.TrimSpace(msg))}
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		statusErr.retryAfter = time.Duration(seconds) * time.Second
+	}
+	return statusErr
+}
+
+func (p *httpPoster) close() {
+	p.client.CloseIdleConnections()
+}
+
+// stderrReporter returns an error callback printing to stderr, prefixed
+// with the output type and destination.
+func stderrReporter(kind, dest string) func(error) {
+	return func(err error) {
+		fmt.Fprintf(os.Stderr, "glog: %s output %s: %v\n", kind, dest, err)
+	}
+}
@@ -0,0 +1,193 @@
+package glog
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Named returns a child of the global logger named name. Entries of the
+// child are filtered by the level configured for name in Config.Levels
+// instead of the global level; dotted names such as "db.pool" fall back to
+// their closest configured parent ("db"), then to the global level.
+//
+// The child writes through the global logger current at each entry, so a
+// child created at startup follows Init and reloads, including changes to
+// Config.Levels. Options such as show_line are taken from the global logger
+// when Named is called. Loggers created by Build support the same per-name
+// levels through (*zap.SugaredLogger).Named.
+//
+// 示例：
+//
+//	// logger.yaml
+//	// log_level: info
+//	// levels:
+//	//   db: debug
+//	//   http: warn
+//	dbLog := glog.Named("db")
+//	dbLog.Debug("query") // 写入
+//	glog.Named("db.pool").Debug("acquire") // 同样写入，继承 db 的级别
+func Named(name string) *zap.SugaredLogger {
+	return followGlobal().Named(name)
+}
+
+// followGlobal returns a logger with the options of the global logger whose
+// entries go to the global logger current at the time, see globalCore.
+func followGlobal() *zap.SugaredLogger {
+	s := getState()
+	logger := s.logger.Desugar()
+	if s.config != nil && s.config.ShowLine {
+		// Undo the caller skip that storeGlobal adds for the package-level functions.
+		logger = logger.WithOptions(zap.AddCallerSkip(-1))
+	}
+	return logger.WithOptions(zap.WrapCore(func(zapcore.Core) zapcore.Core {
+		return &globalCore{}
+	})).Sugar()
+}
+
+// globalCore writes through the core of the global logger installed when
+// an entry is checked, with the fields added by With. Loggers built on it
+// follow Init and reloads, and never write to the writers of a replaced
+// global logger, which storeGlobal closes.
+type globalCore struct {
+	fields []zapcore.Field
+	// cached is the core of the last global logger seen, with fields.
+	cached atomic.Pointer[globalCoreCache]
+}
+
+type globalCoreCache struct {
+	state *loggerState
+	core  zapcore.Core
+}
+
+// current returns the core of the current global logger with c.fields.
+func (c *globalCore) current() zapcore.Core {
+	s := getState()
+	if cached := c.cached.Load(); cached != nil && cached.state == s {
+		return cached.core
+	}
+	if s == nil || s.logger == nil {
+		return zapcore.NewNopCore()
+	}
+	core := s.logger.Desugar().Core()
+	if len(c.fields) > 0 {
+		core = core.With(c.fields)
+	}
+	c.cached.Store(&globalCoreCache{state: s, core: core})
+	return core
+}
+
+func (c *globalCore) Enabled(level zapcore.Level) bool {
+	return c.current().Enabled(level)
+}
+
+func (c *globalCore) With(fields []zapcore.Field) zapcore.Core {
+	return &globalCore{fields: append(append([]zapcore.Field(nil), c.fields...), fields...)}
+}
+
+func (c *globalCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return c.current().Check(ent, ce)
+}
+
+func (c *globalCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return c.current().Write(ent, fields)
+}
+
+func (c *globalCore) Sync() error {
+	return c.current().Sync()
+}
+
+// componentLevels resolves the level of a logger from its name.
+type componentLevels struct {
+	global zap.AtomicLevel
+	levels map[string]zapcore.Level
+	// min is the lowest configured component level.
+	min zapcore.Level
+	// resolved caches the configured level of each logger name seen so far;
+	// names without one map to nil and use the global level.
+	resolved sync.Map
+}
+
+// newComponentLevels returns nil when cfg configures no component levels.
+// Invalid level names are ignored, see Config.Validate.
+func newComponentLevels(cfg *Config, global zap.AtomicLevel) *componentLevels {
+	levels := make(map[string]zapcore.Level, len(cfg.Levels))
+	for name, levelStr := range cfg.Levels {
+		if level, err := parseLevel(levelStr); err == nil && name != "" {
+			levels[name] = level
+		}
+	}
+	if len(levels) == 0 {
+		return nil
+	}
+
+	c := &componentLevels{global: global, levels: levels, min: zapcore.FatalLevel}
+	for _, level := range levels {
+		if level < c.min {
+			c.min = level
+		}
+	}
+	return c
+}
+
+// Enabled reports whether any logger may write at level. It is the level
+// every core is built with; componentCore then filters by logger name.
+func (c *componentLevels) Enabled(level zapcore.Level) bool {
+	return level >= c.min || c.global.Enabled(level)
+}
+
+// enabledFor reports whether the logger called name writes at level.
+func (c *componentLevels) enabledFor(name string, level zapcore.Level) bool {
+	if name == "" {
+		return c.global.Enabled(level)
+	}
+	v, ok := c.resolved.Load(name)
+	if !ok {
+		v, _ = c.resolved.LoadOrStore(name, c.lookup(name))
+	}
+	if configured, ok := v.(zapcore.Level); ok {
+		return configured.Enabled(level)
+	}
+	return c.global.Enabled(level)
+}
+
+// lookup returns the level of name or of its closest dotted parent, or nil.
+func (c *componentLevels) lookup(name string) interface{} {
+	for {
+		if level, ok := c.levels[name]; ok {
+			return level
+		}
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 {
+			return nil
+		}
+		name = name[:i]
+	}
+}
+
+// componentCore drops entries below the level of their logger name.
+type componentCore struct {
+	zapcore.Core
+	levels *componentLevels
+}
+
+func (c *componentCore) With(fields []zapcore.Field) zapcore.Core {
+	return &componentCore{Core: c.Core.With(fields), levels: c.levels}
+}
+
+func (c *componentCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.levels.enabledFor(ent.LoggerName, ent.Level) {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
+
+// withComponentLevels wraps the core of logger in a componentCore.
+func withComponentLevels(logger *zap.SugaredLogger, levels *componentLevels) *zap.SugaredLogger {
+	return logger.Desugar().WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return &componentCore{Core: core, levels: levels}
+	})).Sugar()
+}
@@ -0,0 +1,183 @@
+package glog
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+const componentConfig = `
+encoder: json
+show_line: true
+log_level: info
+separate_levels: false
+levels:
+  db: debug
+  db.cache: error
+  http: warn
+`
+
+func TestNamedComponentLevels(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "glog_test_named")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	configPath := writeConfig(t, tempDir, componentConfig)
+	if err := Init(configPath, tempDir); err != nil {
+		t.Fatalf("Failed to initialize logger: %v", err)
+	}
+
+	Debug("component_root_debug_filtered")
+	Info("component_root_info")
+	Named("db").Debug("component_db_debug")
+	Named("db.pool").Debug("component_db_pool_debug")
+	Named("db.cache").Warn("component_db_cache_warn_filtered")
+	Named("dbx").Debug("component_dbx_debug_filtered")
+	Named("http").Info("component_http_info_filtered")
+	Named("http").With("status", 500).Error("component_http_error")
+	Named("other").Debug("component_other_debug_filtered")
+	Flush() //nolint:errcheck
+
+	content, err := os.ReadFile(filepath.Join(tempDir, "app.log"))
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	for _, want := range []string{
+		"component_root_info", "component_db_debug", "component_db_pool_debug", "component_http_error",
+		`"logger":"db.pool"`, "component_test.go",
+	} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("Log file should contain %q. Content: %s", want, content)
+		}
+	}
+	if strings.Contains(string(content), "filtered") {
+		t.Errorf("Entries below their component level should be filtered. Content: %s", content)
+	}
+}
+
+func TestNamedFollowsReload(t *testing.T) {
+	dirA, dirB := t.TempDir(), t.TempDir()
+	base := &Config{Encoder: "json", LogLevel: "info", SeparateLevels: false}
+
+	cfgA := *base
+	cfgA.Directory, cfgA.Levels = dirA, map[string]string{"db": "debug"}
+	if err := InitWithConfig(&cfgA); err != nil {
+		t.Fatalf("InitWithConfig failed: %v", err)
+	}
+	dbLog := Named("db").With("pool", 1)
+	dbLog.Debug("named_before_reload")
+
+	cfgB := *base
+	cfgB.Directory, cfgB.Levels = dirB, map[string]string{"db": "warn"}
+	if err := InitWithConfig(&cfgB); err != nil {
+		t.Fatalf("InitWithConfig failed: %v", err)
+	}
+	dbLog.Info("named_info_filtered")
+	dbLog.Warn("named_after_reload")
+	Flush() //nolint:errcheck
+
+	a, _ := os.ReadFile(filepath.Join(dirA, "app.log"))
+	b, _ := os.ReadFile(filepath.Join(dirB, "app.log"))
+	if !strings.Contains(string(a), "named_before_reload") || strings.Contains(string(a), "named_after_reload") {
+		t.Errorf("The child should stop writing to the replaced logger. Content: %s", a)
+	}
+	if !strings.Contains(string(b), `"logger":"db"`) || !strings.Contains(string(b), `"pool":1`) || !strings.Contains(string(b), "named_after_reload") {
+		t.Errorf("The child should write to the new logger with its name and fields. Content: %s", b)
+	}
+	if strings.Contains(string(b), "filtered") {
+		t.Errorf("The child should follow the new levels. Content: %s", b)
+	}
+}
+
+func TestBuildLoggerComponentLevels(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "glog_test_named_build")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	var buf strings.Builder
+	logger, err := Build(WithConfig(&Config{
+		Directory:      tempDir,
+		LogLevel:       "warn",
+		SeparateLevels: true,
+		Levels:         map[string]string{"db": "debug"},
+	}), WithWriter(&buf))
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	logger.Info("build_root_info_filtered")
+	logger.Named("db").Debug("build_db_debug")
+	logger.Sync() //nolint:errcheck
+
+	if strings.Contains(buf.String(), "filtered") || !strings.Contains(buf.String(), "build_db_debug") {
+		t.Errorf("Extra writers should follow component levels. Got: %s", buf.String())
+	}
+	checkLogFile(t, filepath.Join(tempDir, FileDebug), "debug", "build_db_debug")
+
+	// Changing the logger level does not affect configured components.
+	logger.SetLevel(zap.InfoLevel)
+	logger.Info("build_root_info_enabled")
+	if !strings.Contains(buf.String(), "build_root_info_enabled") {
+		t.Errorf("Root entries should follow SetLevel. Got: %s", buf.String())
+	}
+}
+
+func TestLevelsFromEnv(t *testing.T) {
+	t.Setenv("GLOG_LEVELS", "db=debug, http = warn")
+
+	cfg := &Config{Levels: map[string]string{"grpc": "error"}}
+	if err := applyEnv(cfg, "GLOG"); err != nil {
+		t.Fatalf("applyEnv failed: %v", err)
+	}
+	if len(cfg.Levels) != 2 || cfg.Levels["db"] != "debug" || cfg.Levels["http"] != "warn" {
+		t.Errorf("Unexpected levels %v", cfg.Levels)
+	}
+
+	t.Setenv("GLOG_LEVELS", "db:debug")
+	if err := applyEnv(&Config{}, "GLOG"); err == nil {
+		t.Error("Expected an error for a malformed GLOG_LEVELS, got nil")
+	}
+}
+
+func TestWithConfigDoesNotModifyLevels(t *testing.T) {
+	t.Setenv("GLOG_LEVELS", "db=debug")
+
+	tempDir, err := os.MkdirTemp("", "glog_test_named_copy")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	levels := map[string]string{"http": "warn"}
+	if _, err := Build(WithConfig(&Config{Directory: tempDir, Levels: levels})); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if len(levels) != 1 || levels["http"] != "warn" {
+		t.Errorf("The caller's levels were modified: %v", levels)
+	}
+}
+
+func TestValidateLevels(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "glog_test_named_validate")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	err = (&Config{Directory: tempDir, Levels: map[string]string{"db": "verbose", "": "info"}}).Validate()
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Expected *ValidationError, got %T: %v", err, err)
+	}
+	if len(verr.Problems) != 2 || !strings.Contains(err.Error(), "levels.db") {
+		t.Errorf("Expected problems for both level entries, got %v", verr.Problems)
+	}
+}
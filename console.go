@@ -0,0 +1,83 @@
+package glog
+
+import (
+	"os"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// isTerminal reports whether f is a terminal. Colored level encoders are
+// only used for terminals; it is a variable so tests can replace it.
+var isTerminal = func(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// writerIsTerminal reports whether w is a terminal.
+func writerIsTerminal(w interface{}) bool {
+	f, ok := w.(*os.File)
+	return ok && isTerminal(f)
+}
+
+// consoleDestination is stdout or stderr.
+type consoleDestination struct {
+	writer zapcore.WriteSyncer
+	tty    bool
+}
+
+// consoleDestinations returns the console destinations enabled in cfg.
+func consoleDestinations(cfg *Config) []consoleDestination {
+	var dests []consoleDestination
+	if cfg.LogStdout {
+		dests = append(dests, consoleDestination{zapcore.AddSync(os.Stdout), isTerminal(os.Stdout)})
+	}
+	if cfg.LogStderr || cfg.StderrOnly {
+		dests = append(dests, consoleDestination{stderr, isTerminal(stderrOrig)})
+	}
+	return dests
+}
+
+// destinationConfig returns cfg with the encoder settings of one
+// destination. Console destinations use ConsoleEncoder and
+// ConsoleEncodeLevel when set, then encoder and encodeLevel override both
+// when not empty. Colors are dropped unless the destination is a terminal.
+func destinationConfig(cfg *Config, console bool, tty bool, encoder, encodeLevel string) *Config {
+	c := *cfg
+	if console {
+		if cfg.ConsoleEncoder != "" {
+			c.Encoder = cfg.ConsoleEncoder
+		}
+		if cfg.ConsoleEncodeLevel != "" {
+			c.EncodeLevel = cfg.ConsoleEncodeLevel
+		}
+	}
+	if encoder != "" {
+		c.Encoder = encoder
+	}
+	if encodeLevel != "" {
+		c.EncodeLevel = encodeLevel
+	}
+	if c.Encoder != "json" {
+		c.Encoder = "console"
+	}
+	if !tty {
+		c.EncodeLevel = withoutColor(c.EncodeLevel)
+	}
+	return &c
+}
+
+// sameEncoding reports whether a and b encode entries identically.
+func sameEncoding(a, b *Config) bool {
+	return a.Encoder == b.Encoder && a.EncodeLevel == b.EncodeLevel
+}
+
+// withoutColor returns the plain variant of a colored level encoder.
+func withoutColor(encodeLevel string) string {
+	switch encodeLevel {
+	case LowercaseColorLevelEncoder:
+		return LowercaseLevelEncoder
+	case CapitalColorLevelEncoder:
+		return CapitalLevelEncoder
+	}
+	return encodeLevel
+}
@@ -0,0 +1,141 @@
+package glog
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// ansiEscape starts every ANSI color code.
+const ansiEscape = "\x1b["
+
+// captureStdout runs fn with os.Stdout redirected to a pipe and returns
+// what was written to it.
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Pipe failed: %v", err)
+	}
+	saved := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = saved }()
+
+	out := make(chan string)
+	go func() {
+		b, _ := io.ReadAll(r)
+		out <- string(b)
+	}()
+	fn()
+	w.Close()
+	return <-out
+}
+
+// fakeTerminal makes every destination look like a terminal.
+func fakeTerminal(t *testing.T) {
+	t.Helper()
+	saved := isTerminal
+	isTerminal = func(*os.File) bool { return true }
+	t.Cleanup(func() { isTerminal = saved })
+}
+
+func TestColorsNeverReachFiles(t *testing.T) {
+	for _, tty := range []bool{false, true} {
+		tempDir, err := os.MkdirTemp("", "glog_test_console_color")
+		if err != nil {
+			t.Fatalf("Failed to create temp dir: %v", err)
+		}
+		defer os.RemoveAll(tempDir)
+
+		stdout := captureStdout(t, func() {
+			if tty {
+				fakeTerminal(t)
+			}
+			logger, err := Build(WithConfig(&Config{
+				Directory:      tempDir,
+				LogStdout:      true,
+				SeparateLevels: true,
+				EncodeLevel:    CapitalColorLevelEncoder,
+			}))
+			if err != nil {
+				t.Fatalf("Build failed: %v", err)
+			}
+			logger.Info("console_color_message")
+		})
+
+		content, err := os.ReadFile(filepath.Join(tempDir, FileInfo))
+		if err != nil {
+			t.Fatalf("Failed to read log file: %v", err)
+		}
+		if strings.Contains(string(content), ansiEscape) || !strings.Contains(string(content), "INFO") {
+			t.Errorf("tty=%v: the log file should hold plain levels. Content: %q", tty, content)
+		}
+		if got := strings.Contains(stdout, ansiEscape); got != tty {
+			t.Errorf("tty=%v: colors on stdout = %v. Stdout: %q", tty, got, stdout)
+		}
+		if !strings.Contains(stdout, "console_color_message") {
+			t.Errorf("tty=%v: stdout should hold the entry. Stdout: %q", tty, stdout)
+		}
+	}
+}
+
+func TestConsoleEncoder(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "glog_test_console_encoder")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	stdout := captureStdout(t, func() {
+		logger, err := Build(WithConfig(&Config{
+			Directory:          tempDir,
+			LogStdout:          true,
+			Encoder:            "json",
+			ConsoleEncoder:     "console",
+			ConsoleEncodeLevel: CapitalLevelEncoder,
+		}))
+		if err != nil {
+			t.Fatalf("Build failed: %v", err)
+		}
+		logger.Warn("console_encoder_message")
+	})
+
+	checkLogFile(t, filepath.Join(tempDir, "app.log"), `"level":"warn"`, "console_encoder_message")
+	if strings.Contains(stdout, `"level"`) || !strings.Contains(stdout, "WARN\tconsole_encoder_message") {
+		t.Errorf("stdout should use the console encoder. Stdout: %q", stdout)
+	}
+}
+
+func TestStdoutOutputColors(t *testing.T) {
+	fakeTerminal(t)
+	tempDir, err := os.MkdirTemp("", "glog_test_console_outputs")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	stdout := captureStdout(t, func() {
+		logger, err := Build(WithConfig(&Config{
+			Directory:   tempDir,
+			EncodeLevel: LowercaseColorLevelEncoder,
+			Outputs:     []OutputConfig{{Type: "stdout"}, {Type: "file"}},
+		}))
+		if err != nil {
+			t.Fatalf("Build failed: %v", err)
+		}
+		logger.Info("output_color_message")
+	})
+
+	if !strings.Contains(stdout, ansiEscape) {
+		t.Errorf("A terminal stdout output should keep colors. Stdout: %q", stdout)
+	}
+	content, err := os.ReadFile(filepath.Join(tempDir, "app.log"))
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	if strings.Contains(string(content), ansiEscape) {
+		t.Errorf("A file output should never get colors. Content: %q", content)
+	}
+}
@@ -0,0 +1,130 @@
+package glog
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// DefaultEnvPrefix is the default prefix of environment variables that
+// override Config fields, e.g. GLOG_LOG_LEVEL or GLOG_SEGMENT_MAX_SIZE.
+const DefaultEnvPrefix = "GLOG"
+
+// envPrefix stores the prefix used when Build is not given WithEnvPrefix.
+var envPrefix atomic.Value
+
+func init() {
+	envPrefix.Store(DefaultEnvPrefix)
+}
+
+// SetEnvPrefix changes the prefix of environment variables used by Init, New,
+// NewLogger and Build. An empty prefix disables environment overrides.
+func SetEnvPrefix(prefix string) {
+	envPrefix.Store(prefix)
+}
+
+// getEnvPrefix returns the package-level environment variable prefix.
+func getEnvPrefix() string {
+	return envPrefix.Load().(string)
+}
+
+// WithEnvPrefix overrides the environment variable prefix for a single Build.
+// An empty prefix disables environment overrides.
+func WithEnvPrefix(prefix string) Option {
+	return func(o *buildOptions) {
+		o.envPrefix = &prefix
+	}
+}
+
+// applyEnv overrides cfg fields from environment variables named
+// <prefix>_<YAML_KEY>, where nested keys are joined with "_".
+// Invalid values are reported together in a single error.
+func applyEnv(cfg *Config, prefix string) error {
+	if prefix == "" {
+		return nil
+	}
+	return applyEnvStruct(reflect.ValueOf(cfg).Elem(), prefix)
+}
+
+func applyEnvStruct(v reflect.Value, prefix string) error {
+	var errs []error
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := yamlName(t.Field(i))
+		if name == "" {
+			continue
+		}
+		key := prefix + "_" + strings.ToUpper(name)
+		field := v.Field(i)
+
+		if field.Kind() == reflect.Struct {
+			if err := applyEnvStruct(field, key); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+
+		raw, ok := os.LookupEnv(key)
+		if !ok {
+			continue
+		}
+		if err := setFromString(field, raw); err != nil {
+			errs = append(errs, fmt.Errorf("invalid value %q for %s: %w", raw, key, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// setFromString parses raw into a string, bool or integer field, or into a
+// map[string]string given as comma-separated key=value pairs such as
+// "db=debug,http=warn".
+func setFromString(field reflect.Value, raw string) error {
+	switch field.Kind() {
+	case reflect.Map:
+		if field.Type().Key().Kind() != reflect.String || field.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported field type %s", field.Type())
+		}
+		m := reflect.MakeMap(field.Type())
+		for _, pair := range strings.Split(raw, ",") {
+			if strings.TrimSpace(pair) == "" {
+				continue
+			}
+			key, value, ok := strings.Cut(pair, "=")
+			if !ok {
+				return fmt.Errorf("expected key=value, got %q", pair)
+			}
+			m.SetMapIndex(reflect.ValueOf(strings.TrimSpace(key)), reflect.ValueOf(strings.TrimSpace(value)))
+		}
+		field.Set(m)
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(strings.TrimSpace(raw))
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(strings.TrimSpace(raw), 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	return nil
+}
+
+// yamlName returns the YAML key of a struct field, or "" if it has none.
+func yamlName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+	if name == "-" {
+		return ""
+	}
+	return name
+}
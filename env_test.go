@@ -0,0 +1,141 @@
+package glog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestApplyEnv(t *testing.T) {
+	t.Setenv("GLOG_LOG_LEVEL", "debug")
+	t.Setenv("GLOG_ENCODER", "json")
+	t.Setenv("GLOG_SHOW_LINE", "true")
+	t.Setenv("GLOG_SEGMENT_MAX_SIZE", "42")
+	t.Setenv("GLOG_SEGMENT_COMPRESS", "1")
+
+	cfg := &Config{LogLevel: "info", Encoder: "console", EncodeLevel: CapitalLevelEncoder}
+	if err := applyEnv(cfg, DefaultEnvPrefix); err != nil {
+		t.Fatalf("applyEnv failed: %v", err)
+	}
+
+	if cfg.LogLevel != "debug" {
+		t.Errorf("Expected LogLevel=debug, got %s", cfg.LogLevel)
+	}
+	if cfg.Encoder != "json" {
+		t.Errorf("Expected Encoder=json, got %s", cfg.Encoder)
+	}
+	if !cfg.ShowLine {
+		t.Error("Expected ShowLine=true")
+	}
+	if cfg.Segment.MaxSize != 42 {
+		t.Errorf("Expected Segment.MaxSize=42, got %d", cfg.Segment.MaxSize)
+	}
+	if !cfg.Segment.Compress {
+		t.Error("Expected Segment.Compress=true")
+	}
+	// Fields without an environment variable are left alone.
+	if cfg.EncodeLevel != CapitalLevelEncoder {
+		t.Errorf("Expected EncodeLevel to be preserved, got %s", cfg.EncodeLevel)
+	}
+}
+
+func TestApplyEnvCustomPrefix(t *testing.T) {
+	t.Setenv("GLOG_LOG_LEVEL", "error")
+	t.Setenv("MYAPP_LOG_LEVEL", "warn")
+
+	cfg := &Config{}
+	if err := applyEnv(cfg, "MYAPP"); err != nil {
+		t.Fatalf("applyEnv failed: %v", err)
+	}
+	if cfg.LogLevel != "warn" {
+		t.Errorf("Expected LogLevel=warn, got %s", cfg.LogLevel)
+	}
+
+	cfg = &Config{LogLevel: "info"}
+	if err := applyEnv(cfg, ""); err != nil {
+		t.Fatalf("applyEnv with empty prefix failed: %v", err)
+	}
+	if cfg.LogLevel != "info" {
+		t.Errorf("Empty prefix should disable overrides, got LogLevel=%s", cfg.LogLevel)
+	}
+}
+
+func TestApplyEnvInvalidValues(t *testing.T) {
+	t.Setenv("GLOG_SHOW_LINE", "maybe")
+	t.Setenv("GLOG_SEGMENT_MAX_AGE", "seven")
+
+	err := applyEnv(&Config{}, DefaultEnvPrefix)
+	if err == nil {
+		t.Fatal("Expected an error for invalid values, got nil")
+	}
+	for _, key := range []string{"GLOG_SHOW_LINE", "GLOG_SEGMENT_MAX_AGE"} {
+		if !strings.Contains(err.Error(), key) {
+			t.Errorf("Error should mention %s, got: %v", key, err)
+		}
+	}
+}
+
+func TestEnvOverridesConfigFile(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "glog_test_env_override")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	configPath := writeConfig(t, tempDir, baseConsoleConfig+"log_level: info\n")
+	t.Setenv("GLOG_LOG_LEVEL", "debug")
+	t.Setenv("GLOG_SEPARATE_LEVELS", "false")
+	// The directory argument wins over the environment.
+	t.Setenv("GLOG_DIRECTORY", filepath.Join(tempDir, "ignored"))
+
+	logger, err := New(configPath, tempDir)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	logger.Debug("env_override_debug_message")
+
+	checkLogFile(t, filepath.Join(tempDir, "app.log"), "DEBUG", "env_override_debug_message")
+}
+
+func TestOptionsOverrideEnv(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "glog_test_env_option")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	t.Setenv("GLOG_LOG_LEVEL", "error")
+	t.Setenv("SVC_LOG_LEVEL", "warn")
+
+	logger, err := Build(WithConfig(&Config{Directory: tempDir}), WithEnvPrefix("SVC"), WithLevel("info"))
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	logger.Info("option_beats_env_message")
+
+	checkLogFile(t, filepath.Join(tempDir, "app.log"), "info", "option_beats_env_message")
+}
+
+func TestSetEnvPrefix(t *testing.T) {
+	defer SetEnvPrefix(DefaultEnvPrefix)
+
+	SetEnvPrefix("")
+	t.Setenv("GLOG_SEGMENT_MAX_SIZE", "not-a-number")
+
+	// With overrides disabled the invalid value must be ignored.
+	tempDir, err := os.MkdirTemp("", "glog_test_env_prefix")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	if _, err := NewFromConfig(&Config{Directory: tempDir}); err != nil {
+		t.Fatalf("NewFromConfig failed with env overrides disabled: %v", err)
+	}
+
+	SetEnvPrefix(DefaultEnvPrefix)
+	if _, err := NewFromConfig(&Config{Directory: tempDir}); err == nil {
+		t.Error("Expected an error for an invalid GLOG_SEGMENT_MAX_SIZE, got nil")
+	}
+}
@@ -0,0 +1,164 @@
+package glog
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// configFlags lists the flags registered by RegisterFlags and the config
+// keys they set. Several flags may set the same key, e.g. -log_dir and the
+// Google glog aliases.
+var configFlags = []struct {
+	name  string
+	key   string
+	usage string
+}{
+	{"log_level", "log_level", "minimum log level: debug, info, warn, error, panic or fatal"},
+	{"log_encoder", "encoder", "log encoder: console or json"},
+	{"log_encode_level", "encode_level", "level encoder: Lowercase, LowercaseColor, Capital or CapitalColor"},
+	{"log_console_encoder", "console_encoder", "encoder of stdout and stderr, defaults to -log_encoder"},
+	{"log_console_encode_level", "console_encode_level", "level encoder of stdout and stderr, defaults to -log_encode_level"},
+	{"log_path", "path", "prefix of the log directory"},
+	{"log_dir", "directory", "directory of the log files, replacing path and directory"},
+	{"log_stdout", "log_stdout", "also write logs to stdout"},
+	{"log_stderr", "log_stderr", "also write logs to stderr"},
+	{"log_stderr_only", "stderr_only", "write logs to stderr instead of log files"},
+	{"log_show_line", "show_line", "show file and line number"},
+	{"log_show_goroutine", "show_goroutine", "show goroutine ID"},
+	{"log_high_performance", "high_performance", "enable high performance mode"},
+	{"log_separate_levels", "separate_levels", "write each level to its own file"},
+	{"log_segment_max_size", "segment.max_size", "max size in MB of a log file before rotation"},
+	{"log_segment_max_age", "segment.max_age", "max age in days of rotated log files"},
+	{"log_segment_max_backups", "segment.max_backups", "max number of rotated log files"},
+	{"log_segment_compress", "segment.compress", "compress rotated log files"},
+	{"log_levels", "levels", "per-component levels, e.g. db=debug,http=warn"},
+	{"log_async", "async.enabled", "write logs from a background goroutine"},
+	{"log_async_queue_size", "async.queue_size", "max number of queued entries in async mode"},
+	{"log_async_policy", "async.policy", "policy of a full async queue: block, drop_newest, drop_oldest or drop_below_level"},
+
+	// Google glog compatible aliases.
+	{"logtostderr", "stderr_only", "log to stderr instead of files (alias of -log_stderr_only)"},
+	{"alsologtostderr", "log_stderr", "log to stderr as well as files (alias of -log_stderr)"},
+	{"v", "verbosity", "default verbosity of glog.V"},
+	{"vmodule", "vmodule", "per-file verbosity, e.g. gopher*=3,net/http/*=2"},
+}
+
+// flagOverrides holds the raw values of the flags set on the command line,
+// keyed by config key. Flags that were not set do not override anything.
+var flagOverrides = struct {
+	sync.Mutex
+	values map[string]string
+}{values: map[string]string{}}
+
+// RegisterFlags binds the logger configuration to flags in fs, or in
+// flag.CommandLine if fs is nil. Flags that are set on the command line
+// take precedence over the config file, environment variables and Build
+// options, including the directory argument of Init, for the global logger
+// (Init, InitWithConfig, New(..., true), Build with AsGlobal and reloads)
+// built after fs.Parse. Other loggers ignore them.
+//
+// Besides -log_level, -log_dir, -log_stdout, -log_separate_levels,
+// -log_segment_max_size and the other -log_* flags, the Google glog flags
+// -logtostderr, -alsologtostderr, -v and -vmodule are registered, so
+// RegisterFlags must not be combined with another package registering them
+// on the same FlagSet.
+//
+// 示例：
+//
+//	glog.RegisterFlags(nil)
+//	flag.Parse()
+//	glog.Init("./logger.yaml", "logs") // -log_dir=/var/log/app 优先于 "logs" 及配置文件中的 path
+//
+// Like in Google glog, -log_dir is the whole log directory: it also clears
+// path, unless -log_path is set as well.
+func RegisterFlags(fs *flag.FlagSet) {
+	if fs == nil {
+		fs = flag.CommandLine
+	}
+	for _, f := range configFlags {
+		field, ok := configField(reflect.ValueOf(&Config{}).Elem(), f.key)
+		if !ok {
+			panic(fmt.Sprintf("glog: flag -%s refers to unknown config key %q", f.name, f.key))
+		}
+		fs.Var(&configFlag{key: f.key, isBool: field.Kind() == reflect.Bool, typ: field.Type()}, f.name, f.usage)
+	}
+}
+
+// configFlag is a flag.Value recording the value of one config key.
+type configFlag struct {
+	key    string
+	isBool bool
+	typ    reflect.Type
+}
+
+func (f *configFlag) String() string {
+	if f == nil {
+		return ""
+	}
+	flagOverrides.Lock()
+	defer flagOverrides.Unlock()
+	return flagOverrides.values[f.key]
+}
+
+func (f *configFlag) Set(raw string) error {
+	// Parse into a scratch value so invalid input is reported by fs.Parse.
+	if err := setFromString(reflect.New(f.typ).Elem(), raw); err != nil {
+		return err
+	}
+	flagOverrides.Lock()
+	defer flagOverrides.Unlock()
+	flagOverrides.values[f.key] = raw
+	return nil
+}
+
+func (f *configFlag) IsBoolFlag() bool {
+	return f.isBool
+}
+
+// applyFlags overrides cfg fields with the flags set on the command line.
+func applyFlags(cfg *Config) error {
+	flagOverrides.Lock()
+	defer flagOverrides.Unlock()
+
+	var errs []error
+	for key, raw := range flagOverrides.values {
+		field, ok := configField(reflect.ValueOf(cfg).Elem(), key)
+		if !ok {
+			continue
+		}
+		if err := setFromString(field, raw); err != nil {
+			errs = append(errs, fmt.Errorf("invalid value %q for %s: %w", raw, key, err))
+		}
+	}
+	// The log directory is path + directory; -log_dir replaces both.
+	if _, ok := flagOverrides.values["directory"]; ok {
+		if _, ok := flagOverrides.values["path"]; !ok {
+			cfg.Path = ""
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// configField returns the field of v for a dotted YAML key like segment.max_size.
+func configField(v reflect.Value, key string) (reflect.Value, bool) {
+	name, rest, nested := strings.Cut(key, ".")
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if yamlName(t.Field(i)) != name {
+			continue
+		}
+		field := v.Field(i)
+		if !nested {
+			return field, true
+		}
+		if field.Kind() != reflect.Struct {
+			return reflect.Value{}, false
+		}
+		return configField(field, rest)
+	}
+	return reflect.Value{}, false
+}
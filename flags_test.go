@@ -0,0 +1,157 @@
+package glog
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// newTestFlagSet registers the logger flags on a fresh FlagSet and clears
+// the parsed values when the test ends.
+func newTestFlagSet(t *testing.T) *flag.FlagSet {
+	t.Helper()
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	RegisterFlags(fs)
+	t.Cleanup(func() {
+		flagOverrides.Lock()
+		flagOverrides.values = map[string]string{}
+		flagOverrides.Unlock()
+	})
+	return fs
+}
+
+func TestRegisterFlagsOverrideConfigFile(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "glog_test_flags")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	configPath := writeConfig(t, tempDir, baseConsoleConfig+"log_level: error\nseparate_levels: true\n")
+	flagDir := filepath.Join(tempDir, "from-flag")
+
+	fs := newTestFlagSet(t)
+	err = fs.Parse([]string{
+		"-log_level=debug", "-log_dir", flagDir, "-log_separate_levels=false",
+		"-log_segment_max_size=7", "-v=2", "-vmodule=gopher*=3",
+	})
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	logger, err := Build(WithConfigFile(configPath), WithDirectory(tempDir), AsGlobal())
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	logger.Debug("flags_debug_message")
+
+	// -log_dir wins over the directory argument, -log_level over the file.
+	checkLogFile(t, filepath.Join(flagDir, "app.log"), "DEBUG", "flags_debug_message")
+
+	// Loggers that are not global ignore the flags.
+	other, err := Build(WithConfigFile(configPath), WithDirectory(tempDir))
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	other.Debug("flags_other_debug_filtered")
+	other.Error("flags_other_error")
+	other.Sync() //nolint:errcheck
+	checkLogFile(t, filepath.Join(tempDir, FileError), "ERROR", "flags_other_error")
+	if content, _ := os.ReadFile(filepath.Join(flagDir, "app.log")); strings.Contains(string(content), "flags_other") {
+		t.Errorf("A non-global logger should not use -log_dir. Content: %s", content)
+	}
+
+	cfg := &Config{}
+	if err := applyFlags(cfg); err != nil {
+		t.Fatalf("applyFlags failed: %v", err)
+	}
+	if cfg.Segment.MaxSize != 7 || cfg.Verbosity != 2 || cfg.VModule != "gopher*=3" || cfg.SeparateLevels {
+		t.Errorf("Unexpected config from flags: %+v", cfg)
+	}
+	// Flags that were not set leave the config alone.
+	if cfg.Encoder != "" || cfg.LogStdout {
+		t.Errorf("Unset flags should not override the config: %+v", cfg)
+	}
+}
+
+func TestLogDirFlagReplacesPath(t *testing.T) {
+	tempDir := t.TempDir()
+	configPath := writeConfig(t, tempDir, "encoder: console\npath: ./logs/\ndirectory: glog\n")
+	flagDir := filepath.Join(tempDir, "from-flag")
+
+	fs := newTestFlagSet(t)
+	if err := fs.Parse([]string{"-log_dir", flagDir}); err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	o := &buildOptions{origin: originFile, cfgPath: configPath, global: true}
+	cfg, err := o.config()
+	if err != nil {
+		t.Fatalf("config failed: %v", err)
+	}
+	if got := cfg.Path + cfg.Directory; got != flagDir {
+		t.Errorf("Expected -log_dir to be the log directory %s, got %s", flagDir, got)
+	}
+
+	// -log_path set as well is kept.
+	if err := fs.Parse([]string{"-log_path", tempDir + "/", "-log_dir", "sub"}); err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if cfg, err = o.config(); err != nil {
+		t.Fatalf("config failed: %v", err)
+	}
+	if got := cfg.Path + cfg.Directory; got != tempDir+"/sub" {
+		t.Errorf("Expected -log_path and -log_dir to be joined, got %s", got)
+	}
+}
+
+func TestGoogleGlogFlagAliases(t *testing.T) {
+	fs := newTestFlagSet(t)
+	if err := fs.Parse([]string{"-logtostderr", "-alsologtostderr"}); err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	cfg := &Config{}
+	if err := applyFlags(cfg); err != nil {
+		t.Fatalf("applyFlags failed: %v", err)
+	}
+	if !cfg.StderrOnly || !cfg.LogStderr {
+		t.Errorf("Expected -logtostderr and -alsologtostderr to be applied, got %+v", cfg)
+	}
+
+	if err := fs.Parse([]string{"-v=loud"}); err == nil {
+		t.Error("Expected Parse to reject a non-numeric -v, got nil")
+	}
+}
+
+func TestStderrOnly(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "glog_test_stderr_only")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	var buf bytes.Buffer
+	saved := stderr
+	stderr = zapcore.AddSync(&buf)
+	defer func() { stderr = saved }()
+
+	logDir := filepath.Join(tempDir, "logs")
+	logger, err := Build(WithConfig(&Config{Directory: logDir, StderrOnly: true}))
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	logger.Info("stderr_only_message")
+
+	if !strings.Contains(buf.String(), "stderr_only_message") {
+		t.Errorf("Expected the entry on stderr, got: %q", buf.String())
+	}
+	if _, err := os.Stat(logDir); !os.IsNotExist(err) {
+		t.Errorf("No log directory should be created with stderr_only, got: %v", err)
+	}
+}
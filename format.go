@@ -0,0 +1,110 @@
+package glog
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Supported config file formats. All formats use the same key names as the
+// yaml tags of Config, e.g. log_level and segment.max_size.
+const (
+	FormatYAML = "yaml"
+	FormatJSON = "json"
+	FormatTOML = "toml"
+)
+
+// WithConfigFormat sets the format of the file given to WithConfigFile
+// instead of detecting it from the file extension.
+func WithConfigFormat(format string) Option {
+	return func(o *buildOptions) {
+		o.format = format
+	}
+}
+
+// Marshal encodes the config in the given format (FormatYAML, FormatJSON or
+// FormatTOML) using the same keys as config files, so the output can be read
+// back by Init. An empty format means YAML; a nil config encodes as its zero
+// value. Secrets such as output headers and URL credentials are encoded
+// as they are.
+func (c *Config) Marshal(format string) ([]byte, error) {
+	if c == nil {
+		c = &Config{}
+	}
+	f, err := configFormat("", format)
+	if err != nil {
+		return nil, err
+	}
+	switch f {
+	case FormatJSON:
+		return json.MarshalIndent(c, "", "  ")
+	case FormatTOML:
+		return toml.Marshal(c)
+	}
+	return yaml.Marshal(c)
+}
+
+// configFormat returns the format of cfgPath. An explicit format wins;
+// otherwise .json and .toml files are detected by extension and everything
+// else is treated as YAML, as before.
+func configFormat(cfgPath string, format string) (string, error) {
+	if format != "" {
+		switch f := strings.ToLower(format); f {
+		case FormatYAML, FormatJSON, FormatTOML:
+			return f, nil
+		case "yml":
+			return FormatYAML, nil
+		}
+		return "", fmt.Errorf("unsupported config format %q (want yaml, json or toml)", format)
+	}
+
+	switch strings.ToLower(filepath.Ext(cfgPath)) {
+	case ".json":
+		return FormatJSON, nil
+	case ".toml":
+		return FormatTOML, nil
+	}
+	return FormatYAML, nil
+}
+
+// readConfigFile decodes the config file at cfgPath, after the files it
+// includes, into cfg and applies the named profile, if any. When strict is
+// set it also returns the keys of the files, including those of their
+// profiles, that do not belong to Config.
+func readConfigFile(cfgPath string, format string, cfg *Config, profile string, strict bool) ([]string, error) {
+	l, err := loadConfigFile(cfgPath, format, cfg, profile, strict)
+	if err != nil {
+		return nil, err
+	}
+	return l.unknown, nil
+}
+
+// loadConfigFile is readConfigFile returning the loader, which also records
+// the files that were read.
+func loadConfigFile(cfgPath string, format string, cfg *Config, profile string, strict bool) (*configLoader, error) {
+	l := &configLoader{cfg: cfg, strict: strict}
+	if err := l.load(cfgPath, format); err != nil {
+		return nil, err
+	}
+	if profile != "" {
+		if err := applyProfile(cfg, l.profiles, profile); err != nil {
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+// unmarshalConfig decodes content in the given format into out.
+func unmarshalConfig(content []byte, format string, out interface{}) error {
+	switch format {
+	case FormatJSON:
+		return json.Unmarshal(content, out)
+	case FormatTOML:
+		return toml.Unmarshal(content, out)
+	}
+	return yaml.Unmarshal(content, out)
+}
@@ -0,0 +1,207 @@
+package glog
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const jsonConfig = `{
+  "encoder": "json",
+  "show_line": false,
+  "encode_level": "Capital",
+  "log_level": "debug",
+  "separate_levels": false,
+  "segment": {"max_size": 10, "max_age": 7, "max_backups": 3, "compress": true}
+}`
+
+const tomlConfig = `
+encoder = "json"
+show_line = false
+encode_level = "Capital"
+log_level = "debug"
+separate_levels = false
+
+[segment]
+max_size = 10
+max_age = 7
+max_backups = 3
+compress = true
+`
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	return path
+}
+
+func TestConfigFormat(t *testing.T) {
+	tests := []struct {
+		path, format, want string
+	}{
+		{"logger.yaml", "", FormatYAML},
+		{"logger.yml", "", FormatYAML},
+		{"logger.conf", "", FormatYAML},
+		{"logger.JSON", "", FormatJSON},
+		{"logger.toml", "", FormatTOML},
+		{"logger.conf", "toml", FormatTOML},
+		{"logger.json", "YML", FormatYAML},
+	}
+	for _, tt := range tests {
+		got, err := configFormat(tt.path, tt.format)
+		if err != nil {
+			t.Errorf("configFormat(%q, %q) failed: %v", tt.path, tt.format, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("configFormat(%q, %q) = %s, want %s", tt.path, tt.format, got, tt.want)
+		}
+	}
+
+	if _, err := configFormat("logger.yaml", "ini"); err == nil {
+		t.Error("Expected an error for an unsupported format, got nil")
+	}
+}
+
+func TestReadConfigFileFormats(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "glog_test_formats")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	want := Segment{MaxSize: 10, MaxAge: 7, MaxBackups: 3, Compress: true}
+	for _, path := range []string{
+		writeFile(t, tempDir, "logger.json", jsonConfig),
+		writeFile(t, tempDir, "logger.toml", tomlConfig),
+	} {
+		cfg := &Config{SeparateLevels: true}
+		if _, err := readConfigFile(path, "", cfg, "", false); err != nil {
+			t.Fatalf("readConfigFile(%s) failed: %v", path, err)
+		}
+		if cfg.Encoder != "json" || cfg.EncodeLevel != CapitalLevelEncoder || cfg.LogLevel != "debug" || cfg.SeparateLevels {
+			t.Errorf("%s: unexpected config %+v", path, cfg)
+		}
+		if cfg.Segment != want {
+			t.Errorf("%s: expected segment %+v, got %+v", path, want, cfg.Segment)
+		}
+	}
+}
+
+func TestInitWithJSONAndTOML(t *testing.T) {
+	for _, name := range []string{"logger.json", "logger.toml"} {
+		t.Run(name, func(t *testing.T) {
+			tempDir, err := os.MkdirTemp("", "glog_test_format_init")
+			if err != nil {
+				t.Fatalf("Failed to create temp dir: %v", err)
+			}
+			defer os.RemoveAll(tempDir)
+
+			content := jsonConfig
+			if filepath.Ext(name) == ".toml" {
+				content = tomlConfig
+			}
+			configPath := writeFile(t, tempDir, name, content)
+
+			logger, err := New(configPath, tempDir)
+			if err != nil {
+				t.Fatalf("New failed: %v", err)
+			}
+			logger.Debug("format_debug_message")
+
+			checkLogFile(t, filepath.Join(tempDir, "app.log"), `"level":"DEBUG"`, "format_debug_message")
+		})
+	}
+}
+
+func TestWithConfigFormat(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "glog_test_format_option")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	configPath := writeFile(t, tempDir, "logger.conf", tomlConfig)
+
+	logger, err := Build(WithConfigFile(configPath), WithConfigFormat(FormatTOML), WithDirectory(tempDir))
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	logger.Debug("format_option_message")
+
+	checkLogFile(t, filepath.Join(tempDir, "app.log"), `"level":"DEBUG"`, "format_option_message")
+
+	if _, err := Build(WithConfigFile(configPath), WithDirectory(tempDir)); err == nil {
+		t.Error("Expected a YAML parse error for a TOML file without WithConfigFormat, got nil")
+	}
+}
+
+func TestStrictModeUnknownKeysJSONAndTOML(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "glog_test_format_strict")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	jsonPath := writeFile(t, tempDir, "logger.json", `{"log_levle": "debug", "segment": {"max_sise": 1}}`)
+	tomlPath := writeFile(t, tempDir, "logger.toml", "log_levle = \"debug\"\n[segment]\nmax_sise = 1\n")
+
+	for _, path := range []string{jsonPath, tomlPath} {
+		_, err := Build(WithConfigFile(path), WithDirectory(tempDir), WithStrict())
+		var verr *ValidationError
+		if !errors.As(err, &verr) {
+			t.Fatalf("%s: expected *ValidationError, got %T: %v", path, err, err)
+		}
+		if len(verr.Problems) != 2 {
+			t.Errorf("%s: expected 2 unknown keys, got %v", path, verr.Problems)
+		}
+	}
+}
+
+func TestConfigMarshalRoundTrip(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "glog_test_marshal")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	want := Config{
+		Encoder:        "json",
+		Directory:      "/var/log/app",
+		EncodeLevel:    CapitalLevelEncoder,
+		LogLevel:       "warn",
+		SeparateLevels: true,
+		Segment:        Segment{MaxSize: 10, MaxAge: 7, Compress: true},
+		Levels:         map[string]string{"db": "debug"},
+		Verbosity:      2,
+		VModule:        "gopher*=3",
+	}
+	for _, format := range []string{FormatYAML, FormatJSON, FormatTOML} {
+		out, err := want.Marshal(format)
+		if err != nil {
+			t.Fatalf("Marshal(%s) failed: %v", format, err)
+		}
+		if !strings.Contains(string(out), "log_level") {
+			t.Errorf("Marshal(%s) should use config file keys, got:\n%s", format, out)
+		}
+
+		path := writeFile(t, tempDir, "logger."+format, string(out))
+		var got Config
+		if _, err := readConfigFile(path, "", &got, "", false); err != nil {
+			t.Fatalf("readConfigFile(%s) failed: %v", format, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s round trip mismatch.\nGot:  %+v\nWant: %+v", format, got, want)
+		}
+	}
+
+	if _, err := want.Marshal("xml"); err == nil {
+		t.Error("Expected an error for an unsupported format, got nil")
+	}
+}
@@ -0,0 +1,103 @@
+package glog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+// Body formats of http outputs, set with the "format" option.
+const (
+	// HTTPFormatNDJSON sends one encoded entry per line.
+	HTTPFormatNDJSON = "ndjson"
+	// HTTPFormatJSONArray sends a JSON array of entries; entries of the
+	// console encoder become JSON strings.
+	HTTPFormatJSONArray = "json_array"
+)
+
+// httpSink posts batches of encoded entries to a URL, see newHTTPSink.
+type httpSink struct {
+	*batcher
+	poster *httpPoster
+	format string
+	json   bool // entries are encoded as JSON objects
+}
+
+// newHTTPSink creates the sink of an http output.
+func newHTTPSink(out OutputConfig) (zap.Sink, error) {
+	s, batch, err := parseHTTPOutput(out)
+	if err != nil {
+		return nil, err
+	}
+	s.batcher = newBatcher(batch, s.sendBatch, stderrReporter("http", out.URL))
+	return s, nil
+}
+
+// parseHTTPOutput reads the options of an http output: the batch and retry
+// options (see batchOptions and newHTTPPoster) and format, ndjson (default)
+// or json_array. Headers are sent with every request.
+func parseHTTPOutput(out OutputConfig) (*httpSink, batchConfig, error) {
+	if out.URL == "" {
+		return nil, batchConfig{}, fmt.Errorf("http output requires a url")
+	}
+	opts := newOutputOptions(out.Options)
+	s := &httpSink{
+		poster: newHTTPPoster(out, opts),
+		format: opts.stringOpt("format", HTTPFormatNDJSON, HTTPFormatNDJSON, HTTPFormatJSONArray),
+		json:   out.Encoder == "json",
+	}
+	batch := batchOptions(opts)
+	return s, batch, opts.err()
+}
+
+func (s *httpSink) Write(p []byte) (int, error) {
+	if err := s.add(batchItem{data: append([]byte(nil), bytes.TrimRight(p, "\n")...)}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Sync sends the pending entries and returns the last failure, if any.
+func (s *httpSink) Sync() error {
+	return s.flush()
+}
+
+func (s *httpSink) Close() error {
+	err := s.close()
+	s.poster.close()
+	return err
+}
+
+func (s *httpSink) sendBatch(items []batchItem) error {
+	var body bytes.Buffer
+	var contentType string
+	switch {
+	case s.format == HTTPFormatJSONArray:
+		contentType = "application/json"
+		body.WriteByte('[')
+		for i, it := range items {
+			if i > 0 {
+				body.WriteByte(',')
+			}
+			if s.json {
+				body.Write(it.data)
+			} else {
+				quoted, _ := json.Marshal(string(it.data))
+				body.Write(quoted)
+			}
+		}
+		body.WriteByte(']')
+	default:
+		contentType = "text/plain; charset=utf-8"
+		if s.json {
+			contentType = "application/x-ndjson"
+		}
+		for _, it := range items {
+			body.Write(it.data)
+			body.WriteByte('\n')
+		}
+	}
+	return s.poster.post(contentType, body.Bytes())
+}
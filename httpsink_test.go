@@ -0,0 +1,267 @@
+package glog
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// collector is an httptest server recording the requests it receives. The
+// statuses are returned in order, then 200.
+type collector struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []*http.Request
+	bodies   []string
+	statuses []int
+	received chan struct{}
+}
+
+func newCollector(t *testing.T, statuses ...int) *collector {
+	t.Helper()
+	c := &collector{statuses: statuses, received: make(chan struct{}, 100)}
+	c.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body io.Reader = r.Body
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			zr, err := gzip.NewReader(r.Body)
+			if err != nil {
+				t.Errorf("Invalid gzip body: %v", err)
+				return
+			}
+			body = zr
+		}
+		content, _ := io.ReadAll(body)
+
+		c.mu.Lock()
+		c.requests = append(c.requests, r)
+		c.bodies = append(c.bodies, string(content))
+		status := http.StatusOK
+		if len(c.statuses) > 0 {
+			status, c.statuses = c.statuses[0], c.statuses[1:]
+		}
+		c.mu.Unlock()
+
+		w.WriteHeader(status)
+		c.received <- struct{}{}
+	}))
+	t.Cleanup(c.Close)
+	return c
+}
+
+// Bodies returns the bodies received so far.
+func (c *collector) Bodies() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.bodies...)
+}
+
+// wait waits for n more requests.
+func (c *collector) wait(t *testing.T, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		select {
+		case <-c.received:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Timed out waiting for request %d of %d", i+1, n)
+		}
+	}
+}
+
+func TestHTTPOutputBatchesBySize(t *testing.T) {
+	c := newCollector(t)
+	logger, err := Build(WithConfig(&Config{
+		Encoder: "json",
+		Outputs: []OutputConfig{{Type: "http", URL: c.URL, Options: map[string]string{
+			"batch_size":  "3",
+			"batch_delay": "1h",
+		}}},
+	}))
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	for i := 0; i < 7; i++ {
+		logger.Infof("batch_entry_%d", i)
+	}
+	c.wait(t, 2)
+	if err := logger.Sync(); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	c.wait(t, 1)
+
+	bodies := c.Bodies()
+	if len(bodies) != 3 {
+		t.Fatalf("Expected 3 requests, got %d: %q", len(bodies), bodies)
+	}
+	for i, want := range []int{3, 3, 1} {
+		lines := strings.Split(strings.TrimSuffix(bodies[i], "\n"), "\n")
+		if len(lines) != want {
+			t.Errorf("Request %d: expected %d lines, got %d: %q", i, want, len(lines), bodies[i])
+		}
+		for _, line := range lines {
+			if !json.Valid([]byte(line)) {
+				t.Errorf("Request %d: line is not JSON: %q", i, line)
+			}
+		}
+	}
+	if got := c.requests[0].Header.Get("Content-Type"); got != "application/x-ndjson" {
+		t.Errorf("Expected Content-Type application/x-ndjson, got %q", got)
+	}
+}
+
+func TestHTTPOutputBatchesByBytesAndDelay(t *testing.T) {
+	c := newCollector(t)
+	sink, err := newHTTPSink(OutputConfig{Type: "http", URL: c.URL, Options: map[string]string{
+		"batch_bytes": "10",
+		"batch_delay": "20ms",
+	}})
+	if err != nil {
+		t.Fatalf("newHTTPSink failed: %v", err)
+	}
+	defer sink.Close()
+
+	// 6 + 6 bytes reach batch_bytes.
+	sink.Write([]byte("first\n"))  //nolint:errcheck
+	sink.Write([]byte("second\n")) //nolint:errcheck
+	c.wait(t, 1)
+
+	// A single entry is sent after batch_delay, without Sync.
+	sink.Write([]byte("third\n")) //nolint:errcheck
+	c.wait(t, 1)
+
+	bodies := c.Bodies()
+	if len(bodies) != 2 || bodies[0] != "first\nsecond\n" || bodies[1] != "third\n" {
+		t.Errorf("Unexpected bodies %q", bodies)
+	}
+}
+
+func TestHTTPOutputJSONArrayGzipHeaders(t *testing.T) {
+	c := newCollector(t)
+	logger, err := Build(WithConfig(&Config{
+		Encoder: "json",
+		Outputs: []OutputConfig{{
+			Type:    "http",
+			URL:     c.URL,
+			Headers: map[string]string{"Authorization": "Bearer secret", "X-Tenant": "team-a"},
+			Options: map[string]string{"format": "json_array", "gzip": "true"},
+		}},
+	}))
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	logger.Info("array_entry_1")
+	logger.Warn("array_entry_2")
+	if err := logger.Sync(); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	c.wait(t, 1)
+
+	req := c.requests[0]
+	for key, want := range map[string]string{
+		"Content-Type":     "application/json",
+		"Content-Encoding": "gzip",
+		"Authorization":    "Bearer secret",
+		"X-Tenant":         "team-a",
+	} {
+		if got := req.Header.Get(key); got != want {
+			t.Errorf("Expected header %s %q, got %q", key, want, got)
+		}
+	}
+
+	var entries []map[string]interface{}
+	if err := json.Unmarshal([]byte(c.Bodies()[0]), &entries); err != nil {
+		t.Fatalf("Body is not a JSON array: %v", err)
+	}
+	if len(entries) != 2 || entries[0]["message"] != "array_entry_1" || entries[1]["message"] != "array_entry_2" {
+		t.Errorf("Unexpected entries %v", entries)
+	}
+}
+
+func TestHTTPOutputJSONArrayOfConsoleLines(t *testing.T) {
+	c := newCollector(t)
+	s := &httpSink{format: HTTPFormatJSONArray, poster: &httpPoster{url: c.URL, client: http.DefaultClient}}
+	if err := s.sendBatch([]batchItem{{data: []byte(`INFO "quoted"`)}, {data: []byte("WARN\ttab")}}); err != nil {
+		t.Fatalf("sendBatch failed: %v", err)
+	}
+
+	var got []string
+	if err := json.Unmarshal([]byte(c.Bodies()[0]), &got); err != nil {
+		t.Fatalf("Body is not a JSON array of strings: %v", err)
+	}
+	if len(got) != 2 || got[0] != `INFO "quoted"` || got[1] != "WARN\ttab" {
+		t.Errorf("Unexpected entries %q", got)
+	}
+}
+
+func TestHTTPOutputRetries(t *testing.T) {
+	c := newCollector(t, http.StatusServiceUnavailable, http.StatusTooManyRequests)
+	sink, err := newHTTPSink(OutputConfig{Type: "http", URL: c.URL, Options: map[string]string{"min_backoff": "1ms"}})
+	if err != nil {
+		t.Fatalf("newHTTPSink failed: %v", err)
+	}
+	defer sink.Close()
+
+	sink.Write([]byte("retried\n")) //nolint:errcheck
+	if err := sink.Sync(); err != nil {
+		t.Fatalf("Sync should succeed after retries, got: %v", err)
+	}
+	bodies := c.Bodies()
+	if len(bodies) != 3 || bodies[2] != "retried\n" {
+		t.Errorf("Expected 3 attempts with the same body, got %q", bodies)
+	}
+}
+
+func TestHTTPOutputGivesUp(t *testing.T) {
+	c := newCollector(t, http.StatusBadRequest, http.StatusInternalServerError, http.StatusInternalServerError)
+	s, batch, err := parseHTTPOutput(OutputConfig{Type: "http", URL: c.URL, Options: map[string]string{
+		"min_backoff": "1ms",
+		"max_retries": "1",
+	}})
+	if err != nil {
+		t.Fatalf("parseHTTPOutput failed: %v", err)
+	}
+	var reported []error
+	s.batcher = newBatcher(batch, s.sendBatch, func(err error) { reported = append(reported, err) })
+	defer s.Close()
+
+	// 400 is not retried.
+	s.Write([]byte("rejected\n")) //nolint:errcheck
+	if err := s.Sync(); err == nil || !strings.Contains(err.Error(), "400") {
+		t.Errorf("Expected a 400 error from Sync, got: %v", err)
+	}
+	// 500 is retried max_retries times.
+	s.Write([]byte("failed\n")) //nolint:errcheck
+	if err := s.Sync(); err == nil || !strings.Contains(err.Error(), "giving up after 2 attempts") {
+		t.Errorf("Expected Sync to give up after 2 attempts, got: %v", err)
+	}
+	if len(c.Bodies()) != 3 {
+		t.Errorf("Expected 3 requests, got %d", len(c.Bodies()))
+	}
+	if len(reported) != 2 || s.dropped.Load() != 2 {
+		t.Errorf("Expected 2 reported errors and 2 dropped entries, got %d and %d", len(reported), s.dropped.Load())
+	}
+}
+
+func TestHTTPOutputOptionErrors(t *testing.T) {
+	out := OutputConfig{Type: "http", URL: "http://127.0.0.1:1", Options: map[string]string{
+		"format":     "xml",
+		"batch_size": "0",
+		"gzip":       "maybe",
+		"compress":   "true",
+	}}
+	problems := out.validate("outputs[0]")
+	if len(problems) != 1 {
+		t.Fatalf("Expected one options problem, got %q", problems)
+	}
+	for _, want := range []string{"compress: unknown option", "format: unknown value", "batch_size", "gzip"} {
+		if !strings.Contains(problems[0], want) {
+			t.Errorf("Problem should mention %q, got: %s", want, problems[0])
+		}
+	}
+}
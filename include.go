@@ -0,0 +1,172 @@
+package glog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// includeKeys are the config file keys naming base files to merge before
+// the file itself. They are synonyms and take a path or a list of paths,
+// relative to the including file:
+//
+//	extends: ../base/logger.yaml
+//	log_level: debug
+const (
+	includeKey = "include"
+	extendsKey = "extends"
+)
+
+// configLoader decodes a config file into cfg after the files it includes.
+type configLoader struct {
+	cfg    *Config
+	strict bool
+	// profiles are merged from every loaded file, later files winning.
+	profiles map[string]map[string]interface{}
+	unknown  []string
+	// chain holds the absolute paths of the files being loaded, outermost
+	// first, to detect include cycles.
+	chain []string
+	// files are the files read so far with their content, e.g. for
+	// FileSource to notice changes of included files.
+	files []loadedFile
+}
+
+// loadedFile is a config file read by a configLoader.
+type loadedFile struct {
+	path    string
+	content []byte
+}
+
+// load decodes the file at path, with the given format or the one detected
+// from its extension. Included files are loaded first, in order, so values
+// of later files win and nested sections such as segment are merged key by
+// key.
+func (l *configLoader) load(path string, format string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	for i, p := range l.chain {
+		if p == abs {
+			return fmt.Errorf("include cycle: %s", strings.Join(append(l.chain[i:], abs), " -> "))
+		}
+	}
+	l.chain = append(l.chain, abs)
+	defer func() { l.chain = l.chain[:len(l.chain)-1] }()
+
+	format, err = configFormat(path, format)
+	if err != nil {
+		return err
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	l.files = append(l.files, loadedFile{path: abs, content: content})
+	raw := map[string]interface{}{}
+	if err := unmarshalConfig(content, format, &raw); err != nil {
+		return err
+	}
+
+	bases, err := includedFiles(raw, path)
+	if err != nil {
+		return err
+	}
+	for _, base := range bases {
+		if err := l.load(base, ""); err != nil {
+			return fmt.Errorf("%s: %w", base, err)
+		}
+	}
+
+	profiles, err := splitProfiles(raw)
+	if err != nil {
+		return err
+	}
+	l.mergeProfiles(profiles)
+
+	if l.strict {
+		unknown := append(unknownKeys(raw, reflect.TypeOf(Config{}), ""), profileUnknownKeys(profiles)...)
+		if len(l.chain) > 1 {
+			// Point at the included file the keys come from.
+			for i := range unknown {
+				unknown[i] = path + ": " + unknown[i]
+			}
+		}
+		l.unknown = append(l.unknown, unknown...)
+	}
+
+	return unmarshalConfig(content, format, l.cfg)
+}
+
+// includedFiles removes the include directives from raw and returns the
+// paths they name, resolved relative to the directory of file.
+func includedFiles(raw map[string]interface{}, file string) ([]string, error) {
+	var paths []string
+	for _, key := range []string{includeKey, extendsKey} {
+		value, ok := raw[key]
+		if !ok {
+			continue
+		}
+		delete(raw, key)
+
+		switch v := value.(type) {
+		case string:
+			paths = append(paths, v)
+		case []interface{}:
+			for _, item := range v {
+				s, ok := item.(string)
+				if !ok {
+					return nil, fmt.Errorf("%s must be a path or a list of paths", key)
+				}
+				paths = append(paths, s)
+			}
+		case nil:
+		default:
+			return nil, fmt.Errorf("%s must be a path or a list of paths", key)
+		}
+	}
+
+	dir := filepath.Dir(file)
+	for i, p := range paths {
+		if p == "" {
+			return nil, fmt.Errorf("empty path in %s", includeKey)
+		}
+		if !filepath.IsAbs(p) {
+			paths[i] = filepath.Join(dir, p)
+		}
+	}
+	return paths, nil
+}
+
+// mergeProfiles merges profiles into the ones loaded so far.
+func (l *configLoader) mergeProfiles(profiles map[string]map[string]interface{}) {
+	if len(profiles) == 0 {
+		return
+	}
+	if l.profiles == nil {
+		l.profiles = make(map[string]map[string]interface{}, len(profiles))
+	}
+	for name, p := range profiles {
+		if existing, ok := l.profiles[name]; ok {
+			mergeMaps(existing, p)
+		} else {
+			l.profiles[name] = p
+		}
+	}
+}
+
+// mergeMaps copies src into dst, merging nested maps key by key.
+func mergeMaps(dst, src map[string]interface{}) {
+	for key, value := range src {
+		if nested, ok := value.(map[string]interface{}); ok {
+			if existing, ok := dst[key].(map[string]interface{}); ok {
+				mergeMaps(existing, nested)
+				continue
+			}
+		}
+		dst[key] = value
+	}
+}
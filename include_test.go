@@ -0,0 +1,155 @@
+package glog
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIncludeLayering(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "glog_test_include")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	if err := os.Mkdir(filepath.Join(tempDir, "base"), 0755); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+	writeFile(t, tempDir, "base/company.yaml", `
+encoder: json
+encode_level: Capital
+log_level: info
+segment:
+  max_size: 100
+  max_age: 30
+levels:
+  db: warn
+profiles:
+  prod:
+    segment:
+      compress: true
+`)
+	writeFile(t, tempDir, "base/team.json", `{"log_level": "warn", "segment": {"max_backups": 5}}`)
+	configPath := writeFile(t, tempDir, "service.yaml", `
+include:
+  - base/company.yaml
+  - base/team.json
+log_level: debug
+segment:
+  max_size: 10
+levels:
+  http: error
+profiles:
+  prod:
+    log_level: error
+`)
+
+	cfg := &Config{}
+	if _, err := readConfigFile(configPath, "", cfg, "prod", false); err != nil {
+		t.Fatalf("readConfigFile failed: %v", err)
+	}
+
+	want := Segment{MaxSize: 10, MaxAge: 30, MaxBackups: 5, Compress: true}
+	if cfg.Segment != want {
+		t.Errorf("Expected merged segment %+v, got %+v", want, cfg.Segment)
+	}
+	if cfg.Encoder != "json" || cfg.EncodeLevel != CapitalLevelEncoder {
+		t.Errorf("Values only set in the base should be kept: %+v", cfg)
+	}
+	// The profile merged from both files wins over everything else.
+	if cfg.LogLevel != "error" {
+		t.Errorf("Expected log_level from the prod profile, got %s", cfg.LogLevel)
+	}
+	if cfg.Levels["db"] != "warn" || cfg.Levels["http"] != "error" {
+		t.Errorf("Expected merged levels, got %v", cfg.Levels)
+	}
+}
+
+func TestExtendsSingleFile(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "glog_test_extends")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	writeFile(t, tempDir, "base.toml", "encoder = \"json\"\nlog_level = \"info\"\nseparate_levels = false\n")
+	configPath := writeFile(t, tempDir, "logger.yaml", "extends: base.toml\nlog_level: debug\n")
+
+	logger, err := Build(WithConfigFile(configPath), WithDirectory(tempDir))
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	logger.Debug("extends_debug_message")
+
+	checkLogFile(t, filepath.Join(tempDir, "app.log"), `"level":"debug"`, "extends_debug_message")
+}
+
+func TestIncludeCycle(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "glog_test_include_cycle")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	writeFile(t, tempDir, "a.yaml", "include: b.yaml\n")
+	writeFile(t, tempDir, "b.yaml", "include: [c.yaml]\n")
+	writeFile(t, tempDir, "c.yaml", "extends: a.yaml\n")
+
+	_, err = readConfigFile(filepath.Join(tempDir, "a.yaml"), "", &Config{}, "", false)
+	if err == nil || !strings.Contains(err.Error(), "include cycle") {
+		t.Fatalf("Expected an include cycle error, got: %v", err)
+	}
+	if !strings.Contains(err.Error(), "a.yaml -> "+filepath.Join(tempDir, "b.yaml")) {
+		t.Errorf("The cycle should be listed, got: %v", err)
+	}
+
+	// Including the same base twice without a cycle is fine.
+	writeFile(t, tempDir, "base.yaml", "log_level: warn\n")
+	writeFile(t, tempDir, "mid.yaml", "include: base.yaml\n")
+	configPath := writeFile(t, tempDir, "top.yaml", "include: [base.yaml, mid.yaml]\n")
+	if _, err := readConfigFile(configPath, "", &Config{}, "", false); err != nil {
+		t.Errorf("Diamond includes should be allowed, got: %v", err)
+	}
+}
+
+func TestIncludeErrors(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "glog_test_include_errors")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	for name, content := range map[string]string{
+		"missing.yaml": "include: does-not-exist.yaml\n",
+		"badtype.yaml": "include: {a: b}\n",
+		"empty.yaml":   "include: [\"\"]\n",
+	} {
+		path := writeFile(t, tempDir, name, content)
+		if _, err := readConfigFile(path, "", &Config{}, "", false); err == nil {
+			t.Errorf("%s: expected an error, got nil", name)
+		}
+	}
+}
+
+func TestStrictModeIncludedFile(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "glog_test_include_strict")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	basePath := writeFile(t, tempDir, "base.yaml", "log_levle: debug\n")
+	configPath := writeFile(t, tempDir, "logger.yaml", "include: base.yaml\nencoder: json\n")
+
+	_, err = Build(WithConfigFile(configPath), WithDirectory(tempDir), WithStrict())
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Expected *ValidationError, got %T: %v", err, err)
+	}
+	if len(verr.Problems) != 1 || !strings.HasPrefix(verr.Problems[0], basePath+": unknown key") {
+		t.Errorf("Expected the unknown key to point at %s, got %v", basePath, verr.Problems)
+	}
+}
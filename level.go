@@ -0,0 +1,149 @@
+package glog
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// globalLevel is shared by the default logger and every logger built with
+// AsGlobal (Init, InitWithConfig, New(..., true), reloads), so the level set
+// with SetLevel applies to package-level functions and to their handles.
+var globalLevel = zap.NewAtomicLevel()
+
+// elevation tracks a temporary level change made by ElevateLevel.
+var elevation elevationState
+
+type elevationState struct {
+	mu        sync.Mutex
+	active    bool
+	restoreTo zapcore.Level
+	until     time.Time
+	timer     *time.Timer
+	// gen identifies the latest elevation so stale timers and cancel
+	// functions of overlapping elevations do nothing.
+	gen uint64
+}
+
+// SetLevel changes the level of the global logger at runtime.
+// It works in both separate_levels and high_performance modes.
+//
+// SetLevel cancels a pending ElevateLevel, so the explicitly set level stays.
+func SetLevel(level zapcore.Level) {
+	elevation.mu.Lock()
+	defer elevation.mu.Unlock()
+	elevation.stopLocked()
+	globalLevel.SetLevel(level)
+}
+
+// GetLevel returns the current level of the global logger.
+func GetLevel() zapcore.Level {
+	return globalLevel.Level()
+}
+
+// ElevateLevel sets the global level to level for ttl and then automatically
+// restores the level that was active before. Both transitions are logged.
+//
+// Overlapping elevations are safe: a new elevation replaces the level and
+// the deadline of the current one, but the level restored at the end is
+// still the one from before the first elevation. Init and hot reloads during
+// an elevation update the level that will be restored. The returned function
+// ends the elevation early; it does nothing once the elevation has expired
+// or has been replaced. A non-positive ttl does not change the level.
+//
+// 示例：
+//
+//	glog.ElevateLevel(zap.DebugLevel, 10*time.Minute)
+func ElevateLevel(level zapcore.Level, ttl time.Duration) (cancel func()) {
+	if ttl <= 0 {
+		return func() {}
+	}
+
+	elevation.mu.Lock()
+	defer elevation.mu.Unlock()
+
+	if !elevation.active {
+		elevation.restoreTo = globalLevel.Level()
+	}
+	elevation.stopLocked()
+	elevation.active = true
+	elevation.until = time.Now().Add(ttl)
+	gen := elevation.gen
+
+	globalLevel.SetLevel(level)
+	Warnf("log level elevated to %s for %s, %s will be restored afterwards", level, ttl, elevation.restoreTo)
+
+	elevation.timer = time.AfterFunc(ttl, func() {
+		elevation.end(gen, "expired")
+	})
+	return func() {
+		elevation.end(gen, "cancelled")
+	}
+}
+
+// end restores the level saved by the elevation identified by gen.
+func (e *elevationState) end(gen uint64, reason string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if !e.active || e.gen != gen {
+		return
+	}
+	Warnf("log level elevation to %s %s, restoring %s", globalLevel.Level(), reason, e.restoreTo)
+	globalLevel.SetLevel(e.restoreTo)
+	e.stopLocked()
+}
+
+// stopLocked forgets the current elevation without restoring its level.
+func (e *elevationState) stopLocked() {
+	if e.timer != nil {
+		e.timer.Stop()
+		e.timer = nil
+	}
+	e.active = false
+	e.gen++
+}
+
+// setBaseLevel applies the configured level of a new global logger. During
+// an elevation it becomes the level to restore instead.
+func setBaseLevel(level zapcore.Level) {
+	elevation.mu.Lock()
+	defer elevation.mu.Unlock()
+	if elevation.active {
+		elevation.restoreTo = level
+		return
+	}
+	globalLevel.SetLevel(level)
+}
+
+// elevationStatus reports the active elevation, if any.
+func elevationStatus() (active bool, restoreTo zapcore.Level, until time.Time) {
+	elevation.mu.Lock()
+	defer elevation.mu.Unlock()
+	return elevation.active, elevation.restoreTo, elevation.until
+}
+
+// SetLevel changes the level of the logger at runtime. Loggers that share the
+// global level (built with AsGlobal) change the global level as well, like
+// the package-level SetLevel, cancelling a pending ElevateLevel.
+//
+// It has no effect on a Logger that was not created by Build, NewLogger or
+// NewLoggerFromConfig.
+func (l *Logger) SetLevel(level zapcore.Level) {
+	switch {
+	case l.level == nil:
+	case *l.level == globalLevel:
+		SetLevel(level)
+	default:
+		l.level.SetLevel(level)
+	}
+}
+
+// GetLevel returns the current level of the logger.
+func (l *Logger) GetLevel() zapcore.Level {
+	if l.level != nil {
+		return l.level.Level()
+	}
+	return zapcore.LevelOf(l.Desugar().Core())
+}
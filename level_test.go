@@ -0,0 +1,289 @@
+package glog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestSetLevelSeparateLevels(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "glog_test_setlevel")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	configPath := writeConfig(t, tempDir, baseConsoleConfig+"log_level: info\n")
+	if err := Init(configPath, tempDir); err != nil {
+		t.Fatalf("Failed to initialize logger: %v", err)
+	}
+	defer SetLevel(zap.InfoLevel)
+
+	if GetLevel() != zap.InfoLevel {
+		t.Errorf("Expected global level info after Init, got %s", GetLevel())
+	}
+
+	Debug("setlevel_debug_filtered")
+	SetLevel(zap.DebugLevel)
+	if GetLevel() != zap.DebugLevel {
+		t.Errorf("Expected global level debug, got %s", GetLevel())
+	}
+	Debug("setlevel_debug_enabled")
+
+	SetLevel(zap.ErrorLevel)
+	Warn("setlevel_warn_filtered")
+	Flush() //nolint:errcheck
+
+	content, _ := os.ReadFile(filepath.Join(tempDir, FileDebug))
+	if strings.Contains(string(content), "setlevel_debug_filtered") {
+		t.Errorf("Debug message before SetLevel should be filtered. Content: %s", content)
+	}
+	if !strings.Contains(string(content), "setlevel_debug_enabled") {
+		t.Errorf("Debug message after SetLevel(debug) should be written. Content: %s", content)
+	}
+	content, _ = os.ReadFile(filepath.Join(tempDir, FileWarn))
+	if strings.Contains(string(content), "setlevel_warn_filtered") {
+		t.Errorf("Warn message after SetLevel(error) should be filtered. Content: %s", content)
+	}
+}
+
+func TestSetLevelHighPerformance(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "glog_test_setlevel_hp")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	configPath := writeConfig(t, tempDir, baseConsoleConfig+"high_performance: true\nlog_level: warn\n")
+	if err := Init(configPath, tempDir); err != nil {
+		t.Fatalf("Failed to initialize logger: %v", err)
+	}
+	defer SetLevel(zap.InfoLevel)
+
+	Info("setlevel_hp_filtered")
+	SetLevel(zap.InfoLevel)
+	Info("setlevel_hp_enabled")
+	Flush() //nolint:errcheck
+
+	content, err := os.ReadFile(filepath.Join(tempDir, "app.log"))
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	if strings.Contains(string(content), "setlevel_hp_filtered") {
+		t.Errorf("Info message before SetLevel should be filtered. Content: %s", content)
+	}
+	if !strings.Contains(string(content), "setlevel_hp_enabled") {
+		t.Errorf("Info message after SetLevel(info) should be written. Content: %s", content)
+	}
+}
+
+func TestSetLevelSharedWithGlobalHandle(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "glog_test_setlevel_shared")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	configPath := writeConfig(t, tempDir, baseConsoleConfig+"log_level: info\n")
+	logger, err := New(configPath, tempDir, true)
+	if err != nil {
+		t.Fatalf("New(true) failed: %v", err)
+	}
+	defer SetLevel(zap.InfoLevel)
+
+	SetLevel(zap.DebugLevel)
+	logger.Debug("setlevel_shared_handle_debug")
+
+	checkLogFile(t, filepath.Join(tempDir, FileDebug), "DEBUG", "setlevel_shared_handle_debug")
+}
+
+func TestLoggerSetLevel(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "glog_test_logger_setlevel")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	globalBefore := GetLevel()
+
+	logger, err := NewLoggerFromConfig(&Config{Directory: tempDir, LogLevel: "error"})
+	if err != nil {
+		t.Fatalf("NewLoggerFromConfig failed: %v", err)
+	}
+	if logger.GetLevel() != zap.ErrorLevel {
+		t.Errorf("Expected instance level error, got %s", logger.GetLevel())
+	}
+
+	logger.Info("logger_setlevel_filtered")
+	logger.SetLevel(zap.InfoLevel)
+	logger.Info("logger_setlevel_enabled")
+
+	if GetLevel() != globalBefore {
+		t.Errorf("Instance SetLevel should not change the global level, got %s", GetLevel())
+	}
+
+	content, err := os.ReadFile(filepath.Join(tempDir, "app.log"))
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	if strings.Contains(string(content), "logger_setlevel_filtered") {
+		t.Errorf("Info message before SetLevel should be filtered. Content: %s", content)
+	}
+	if !strings.Contains(string(content), "logger_setlevel_enabled") {
+		t.Errorf("Info message after SetLevel(info) should be written. Content: %s", content)
+	}
+}
+
+func TestLoggerGetLevelWithoutAtomicLevel(t *testing.T) {
+	logger := &Logger{SugaredLogger: zap.NewNop().Sugar()}
+	// Must not panic for a Logger that was not created by Build.
+	logger.SetLevel(zap.DebugLevel)
+	_ = logger.GetLevel()
+}
+
+// waitForLevel polls the global level until it equals want.
+func waitForLevel(t *testing.T, want zapcore.Level) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for GetLevel() != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for level %s, got %s", want, GetLevel())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestElevateLevelRestores(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "glog_test_elevate")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	configPath := writeConfig(t, tempDir, baseConsoleConfig+"log_level: info\n")
+	if err := Init(configPath, tempDir); err != nil {
+		t.Fatalf("Failed to initialize logger: %v", err)
+	}
+
+	ElevateLevel(zap.DebugLevel, 50*time.Millisecond)
+	if GetLevel() != zap.DebugLevel {
+		t.Fatalf("Expected elevated level debug, got %s", GetLevel())
+	}
+	Debug("elevate_debug_visible")
+
+	waitForLevel(t, zap.InfoLevel)
+	Debug("elevate_debug_hidden")
+	Flush() //nolint:errcheck
+
+	content, _ := os.ReadFile(filepath.Join(tempDir, FileDebug))
+	if !strings.Contains(string(content), "elevate_debug_visible") {
+		t.Errorf("Debug message during elevation should be written. Content: %s", content)
+	}
+	if strings.Contains(string(content), "elevate_debug_hidden") {
+		t.Errorf("Debug message after elevation should be filtered. Content: %s", content)
+	}
+
+	// Both transitions are logged.
+	content, _ = os.ReadFile(filepath.Join(tempDir, FileWarn))
+	if !strings.Contains(string(content), "log level elevated to debug") || !strings.Contains(string(content), "expired, restoring info") {
+		t.Errorf("Expected both level transitions to be logged. Content: %s", content)
+	}
+}
+
+func TestElevateLevelOverlapping(t *testing.T) {
+	SetLevel(zap.WarnLevel)
+	defer SetLevel(zap.InfoLevel)
+
+	cancelFirst := ElevateLevel(zap.InfoLevel, time.Hour)
+	ElevateLevel(zap.DebugLevel, 50*time.Millisecond)
+	if GetLevel() != zap.DebugLevel {
+		t.Fatalf("Expected level debug after second elevation, got %s", GetLevel())
+	}
+
+	// The first elevation was replaced, its cancel must not restore anything.
+	cancelFirst()
+	if GetLevel() != zap.DebugLevel {
+		t.Errorf("Stale cancel should be a no-op, got level %s", GetLevel())
+	}
+
+	// The original level, not the first elevated one, is restored.
+	waitForLevel(t, zap.WarnLevel)
+}
+
+func TestElevateLevelCancel(t *testing.T) {
+	SetLevel(zap.ErrorLevel)
+	defer SetLevel(zap.InfoLevel)
+
+	cancel := ElevateLevel(zap.DebugLevel, time.Hour)
+	cancel()
+	if GetLevel() != zap.ErrorLevel {
+		t.Errorf("Expected level error after cancel, got %s", GetLevel())
+	}
+	if active, _, _ := elevationStatus(); active {
+		t.Error("Elevation should be inactive after cancel")
+	}
+
+	if ElevateLevel(zap.DebugLevel, 0); GetLevel() != zap.ErrorLevel {
+		t.Errorf("Non-positive ttl should not change the level, got %s", GetLevel())
+	}
+}
+
+func TestSetLevelCancelsElevation(t *testing.T) {
+	SetLevel(zap.InfoLevel)
+	defer SetLevel(zap.InfoLevel)
+
+	ElevateLevel(zap.DebugLevel, 20*time.Millisecond)
+	SetLevel(zap.WarnLevel)
+	time.Sleep(60 * time.Millisecond)
+
+	if GetLevel() != zap.WarnLevel {
+		t.Errorf("Explicit SetLevel should survive the elevation timer, got %s", GetLevel())
+	}
+}
+
+func TestGlobalLoggerSetLevelCancelsElevation(t *testing.T) {
+	logger, err := Build(WithConfig(&Config{Directory: t.TempDir()}), AsGlobal())
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	SetLevel(zap.InfoLevel)
+	defer SetLevel(zap.InfoLevel)
+
+	ElevateLevel(zap.DebugLevel, 20*time.Millisecond)
+	logger.SetLevel(zap.WarnLevel)
+	time.Sleep(60 * time.Millisecond)
+
+	if GetLevel() != zap.WarnLevel || logger.GetLevel() != zap.WarnLevel {
+		t.Errorf("(*Logger).SetLevel should survive the elevation timer, got %s", GetLevel())
+	}
+}
+
+func TestInitDuringElevationUpdatesRestoreLevel(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "glog_test_elevate_init")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+	defer SetLevel(zap.InfoLevel)
+
+	SetLevel(zap.InfoLevel)
+	cancel := ElevateLevel(zap.DebugLevel, time.Hour)
+
+	configPath := writeConfig(t, tempDir, baseConsoleConfig+"log_level: error\n")
+	if err := Init(configPath, tempDir); err != nil {
+		t.Fatalf("Failed to initialize logger: %v", err)
+	}
+	if GetLevel() != zap.DebugLevel {
+		t.Errorf("Init during elevation should keep the elevated level, got %s", GetLevel())
+	}
+
+	cancel()
+	if GetLevel() != zap.ErrorLevel {
+		t.Errorf("Expected the level from the new config to be restored, got %s", GetLevel())
+	}
+}
@@ -2,7 +2,9 @@ package glog
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"runtime"
 	"strings"
@@ -34,18 +36,37 @@ const (
 
 // Config for glog
 type Config struct {
-	Encoder         string  `yaml:"encoder"`
-	Path            string  `yaml:"path"`
-	Directory       string  `yaml:"directory"`
-	ShowLine        bool    `yaml:"show_line"`
-	ShowGoroutine   bool    `yaml:"show_goroutine"`
-	EncodeLevel     string  `yaml:"encode_level"`
-	StacktraceKey   string  `yaml:"stacktrace_key"`
-	LogStdout       bool    `yaml:"log_stdout"`
-	HighPerformance bool    `yaml:"high_performance"`
-	SeparateLevels  bool    `yaml:"separate_levels"`
-	LogLevel        string  `yaml:"log_level"`
-	Segment         Segment `yaml:"segment"`
+	Encoder         string  `yaml:"encoder" json:"encoder" toml:"encoder"`
+	Path            string  `yaml:"path" json:"path" toml:"path"`
+	Directory       string  `yaml:"directory" json:"directory" toml:"directory"`
+	ShowLine        bool    `yaml:"show_line" json:"show_line" toml:"show_line"`
+	ShowGoroutine   bool    `yaml:"show_goroutine" json:"show_goroutine" toml:"show_goroutine"`
+	EncodeLevel     string  `yaml:"encode_level" json:"encode_level" toml:"encode_level"`
+	StacktraceKey   string  `yaml:"stacktrace_key" json:"stacktrace_key" toml:"stacktrace_key"`
+	LogStdout       bool    `yaml:"log_stdout" json:"log_stdout" toml:"log_stdout"`
+	HighPerformance bool    `yaml:"high_performance" json:"high_performance" toml:"high_performance"`
+	SeparateLevels  bool    `yaml:"separate_levels" json:"separate_levels" toml:"separate_levels"`
+	LogLevel        string  `yaml:"log_level" json:"log_level" toml:"log_level"`
+	Segment         Segment `yaml:"segment" json:"segment" toml:"segment"`
+	// ConsoleEncoder and ConsoleEncodeLevel override Encoder and EncodeLevel
+	// for stdout and stderr, so e.g. colors never reach the log files.
+	ConsoleEncoder     string `yaml:"console_encoder" json:"console_encoder" toml:"console_encoder"`
+	ConsoleEncodeLevel string `yaml:"console_encode_level" json:"console_encode_level" toml:"console_encode_level"`
+	// LogStderr also writes logs to stderr. StderrOnly writes them to stderr
+	// (and to stdout with LogStdout) instead of log files.
+	LogStderr  bool `yaml:"log_stderr" json:"log_stderr" toml:"log_stderr"`
+	StderrOnly bool `yaml:"stderr_only" json:"stderr_only" toml:"stderr_only"`
+	// Levels overrides LogLevel for named loggers, e.g. {"db": "debug"}, see Named.
+	Levels map[string]string `yaml:"levels,omitempty" json:"levels,omitempty" toml:"levels,omitempty"`
+	// Verbosity is the default level of V, VModule overrides it per source
+	// file, e.g. "gopher*=3,net/http/*=2".
+	Verbosity int    `yaml:"verbosity" json:"verbosity" toml:"verbosity"`
+	VModule   string `yaml:"vmodule" json:"vmodule" toml:"vmodule"`
+	// Outputs, if set, replace the log files selected by separate_levels and
+	// log_stdout with the listed sinks, see OutputConfig and RegisterSink.
+	Outputs []OutputConfig `yaml:"outputs,omitempty" json:"outputs,omitempty" toml:"outputs,omitempty"`
+	// Async writes entries from a background goroutine, see AsyncConfig.
+	Async AsyncConfig `yaml:"async" json:"async" toml:"async"`
 }
 
 // setDefaults sets default values for config options
@@ -59,18 +80,94 @@ func (c *Config) setDefaults() {
 	}
 }
 
+// clone returns a deep copy of c, so callers can never modify a Config
+// they did not create.
+func (c *Config) clone() *Config {
+	clone := *c
+	if c.Levels != nil {
+		clone.Levels = make(map[string]string, len(c.Levels))
+		for name, level := range c.Levels {
+			clone.Levels[name] = level
+		}
+	}
+	if c.Outputs != nil {
+		clone.Outputs = make([]OutputConfig, len(c.Outputs))
+		for i, out := range c.Outputs {
+			if out.Segment != nil {
+				segment := *out.Segment
+				out.Segment = &segment
+			}
+			if out.Options != nil {
+				options := make(map[string]string, len(out.Options))
+				for k, v := range out.Options {
+					options[k] = v
+				}
+				out.Options = options
+			}
+			if out.Headers != nil {
+				headers := make(map[string]string, len(out.Headers))
+				for k, v := range out.Headers {
+					headers[k] = v
+				}
+				out.Headers = headers
+			}
+			clone.Outputs[i] = out
+		}
+	}
+	return &clone
+}
+
 // Segment config for log rotation
 type Segment struct {
-	MaxSize    int  `yaml:"max_size"`
-	MaxAge     int  `yaml:"max_age"`
-	MaxBackups int  `yaml:"max_backups"`
-	Compress   bool `yaml:"compress"`
+	MaxSize    int  `yaml:"max_size" json:"max_size" toml:"max_size"`
+	MaxAge     int  `yaml:"max_age" json:"max_age" toml:"max_age"`
+	MaxBackups int  `yaml:"max_backups" json:"max_backups" toml:"max_backups"`
+	Compress   bool `yaml:"compress" json:"compress" toml:"compress"`
 }
 
 // loggerState holds the logger and its associated configuration atomically.
 type loggerState struct {
 	logger        *zap.SugaredLogger
 	showGoroutine bool
+	// config is the effective config logger was built with; nil for the default logger.
+	config *Config
+	// writers closes the files and sinks opened for logger when it is
+	// replaced, see storeGlobal.
+	writers io.Closer
+	// verbosity gates V; nil means verbosity 0 everywhere.
+	verbosity *verbosity
+	// async is the queue of an async logger, nil otherwise.
+	async *asyncQueue
+}
+
+// writerSet tracks the writers opened while building a logger.
+type writerSet []io.Closer
+
+// Close closes every writer and returns the combined error.
+func (ws writerSet) Close() error {
+	var errs []error
+	for _, w := range ws {
+		if err := w.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// writerCloser closes a writerSet once. It is shared by a Logger and the
+// global state it is installed in, so (*Logger).Close and replacing the
+// global logger do not close the writers twice.
+type writerCloser struct {
+	once    sync.Once
+	writers writerSet
+	err     error
+}
+
+func (c *writerCloser) Close() error {
+	c.once.Do(func() {
+		c.err = c.writers.Close()
+	})
+	return c.err
 }
 
 var (
@@ -88,16 +185,28 @@ var (
 	// stderrFile tracks the file used for panic redirect so it can be closed on re-init.
 	stderrFileMu sync.Mutex
 	stderrFile   *os.File
+
+	// stderr is the original standard error; panicRedirect replaces os.Stderr.
+	stderrOrig = os.Stderr
+	stderr     = zapcore.Lock(stderrOrig)
 )
 
 // Logger wraps zap.SugaredLogger to provide additional methods
 type Logger struct {
 	*zap.SugaredLogger
+	// level is the runtime-adjustable level of loggers created by Build.
+	level *zap.AtomicLevel
+	// async is the queue of loggers built with Config.Async, nil otherwise.
+	async *asyncQueue
+	// writers closes the files and sinks opened by Build, see Close.
+	writers *writerCloser
 }
 
 func init() {
 	// Default logger - use production mode for performance
-	logger, _ := zap.NewProduction()
+	prodCfg := zap.NewProductionConfig()
+	prodCfg.Level = globalLevel
+	logger, _ := prodCfg.Build()
 	currentState.Store(&loggerState{
 		logger:        logger.Sugar(),
 		showGoroutine: false,
@@ -112,6 +221,29 @@ func getState() *loggerState {
 	return nil
 }
 
+// CurrentConfig returns a copy of the effective config of the global logger,
+// after defaults, environment variables, options and flags were applied.
+// It returns nil while the default logger is in use, i.e. before Init.
+//
+// LogLevel is the level the logger was built with; see GetLevel for the
+// level currently in effect after SetLevel or ElevateLevel.
+//
+// The copy holds secrets as configured, such as output headers with tokens
+// and credentials in output URLs; AdminHandler redacts them, but take care
+// when logging or exposing the config otherwise.
+//
+// 示例：
+//
+//	out, _ := glog.CurrentConfig().Marshal(glog.FormatYAML)
+//	glog.Infof("logger config:\n%s", out)
+func CurrentConfig() *Config {
+	s := getState()
+	if s == nil || s.config == nil {
+		return nil
+	}
+	return s.config.clone()
+}
+
 // getGoroutineID returns the current goroutine ID.
 // It parses the goroutine ID from the runtime stack trace.
 func getGoroutineID() string {
@@ -154,35 +286,31 @@ func parseGoroutineID(stack []byte) string {
 // Init initializes a new logger with the given config file path and directory.
 // This will replace the default logger.
 func Init(cfgPath string, directory string) error {
-	cfg := &Config{SeparateLevels: true}
-	if err := yamlToStruct(cfgPath, cfg); err != nil {
-		return fmt.Errorf("failed to parse config file: %w", err)
-	}
-	cfg.Directory = directory
-	cfg.setDefaults()
+	_, err := Build(WithConfigFile(cfgPath), WithDirectory(directory), AsGlobal())
+	return err
+}
 
-	logger, err := newLogger(cfg)
-	if err != nil {
-		return err
-	}
-	globalLogger := logger
-	if cfg.ShowLine {
-		globalLogger = logger.Desugar().WithOptions(zap.AddCallerSkip(1)).Sugar()
-	}
-	currentState.Store(&loggerState{
-		logger:        globalLogger,
-		showGoroutine: cfg.ShowGoroutine,
-	})
-	return nil
+// InitWithConfig initializes a new logger from an already populated Config
+// instead of a config file. This will replace the default logger.
+//
+// The given Config is copied before defaults are applied, so the caller's
+// value is left untouched.
+func InitWithConfig(cfg *Config) error {
+	_, err := Build(WithConfig(cfg), AsGlobal())
+	return err
 }
 
 // New creates a new logger with the given config file path and directory.
+// A logger that is not made global cannot be closed: its files stay open
+// and the goroutines of network outputs and async writing keep running, so
+// use NewLogger or Build when it must be closed, see (*Logger).Close.
 //
 // 可选参数 setGlobal（默认 false）控制是否同时替换全局 logger：
 //   - setGlobal = false（默认）：仅返回独立 logger 句柄，不影响全局状态，
 //     与原行为完全一致，适合多实例或局部使用场景。
 //   - setGlobal = true：在返回句柄的同时将新建 logger 设为全局默认，
 //     之后可在任意位置直接调用 glog.Info()、glog.Error() 等包级函数。
+//     返回的句柄始终写入当前的全局 logger，随 Init 与热重载切换。
 //
 // 示例：
 //
@@ -194,49 +322,62 @@ func Init(cfgPath string, directory string) error {
 //	logger, err := glog.New("config.yaml", "./logs", true)
 //	glog.Info("hello") // 生效
 func New(cfgPath string, directory string, setGlobal ...bool) (*zap.SugaredLogger, error) {
-	cfg := &Config{SeparateLevels: true}
-	if err := yamlToStruct(cfgPath, cfg); err != nil {
-		return nil, fmt.Errorf("failed to parse config file: %w", err)
-	}
-	cfg.Directory = directory
-	cfg.setDefaults()
-
-	logger, err := newLogger(cfg)
-	if err != nil {
-		return nil, err
-	}
-
-	// 仅当调用方显式传入 true 时才更新全局 logger
-	if len(setGlobal) > 0 && setGlobal[0] {
-		globalLogger := logger
-		if cfg.ShowLine {
-			globalLogger = logger.Desugar().WithOptions(zap.AddCallerSkip(1)).Sugar()
-		}
-		currentState.Store(&loggerState{
-			logger:        globalLogger,
-			showGoroutine: cfg.ShowGoroutine,
-		})
-	}
+	return newSugared(setGlobal, WithConfigFile(cfgPath), WithDirectory(directory))
+}
 
-	return logger, nil
+// NewFromConfig creates a new logger from an already populated Config.
+// The optional setGlobal argument has the same meaning as in New.
+func NewFromConfig(cfg *Config, setGlobal ...bool) (*zap.SugaredLogger, error) {
+	return newSugared(setGlobal, WithConfig(cfg))
 }
 
 // NewLogger creates a new Logger instance with the given config file path and directory.
 // This returns a Logger wrapper that supports Printf method.
 func NewLogger(cfgPath string, directory string) (*Logger, error) {
-	cfg := &Config{SeparateLevels: true}
-	if err := yamlToStruct(cfgPath, cfg); err != nil {
-		return nil, fmt.Errorf("failed to parse config file: %w", err)
-	}
-	cfg.Directory = directory
-	cfg.setDefaults()
+	return Build(WithConfigFile(cfgPath), WithDirectory(directory))
+}
 
-	sugaredLogger, err := newLogger(cfg)
+// NewLoggerFromConfig creates a new Logger instance from an already populated Config.
+func NewLoggerFromConfig(cfg *Config) (*Logger, error) {
+	return Build(WithConfig(cfg))
+}
+
+// newSugared builds a logger for New and NewFromConfig.
+func newSugared(setGlobal []bool, opts ...Option) (*zap.SugaredLogger, error) {
+	// 仅当调用方显式传入 true 时才更新全局 logger
+	if len(setGlobal) > 0 && setGlobal[0] {
+		opts = append(opts, AsGlobal())
+	}
+	logger, err := Build(opts...)
 	if err != nil {
 		return nil, err
 	}
+	return logger.SugaredLogger, nil
+}
 
-	return &Logger{SugaredLogger: sugaredLogger}, nil
+// storeGlobal replaces the global logger state with logger and then closes
+// the writers of the previous global logger. Handles derived from the global
+// logger (Named, WithContext, New(..., true), Build with AsGlobal) write
+// through globalCore, so nothing keeps using the closed writers.
+func storeGlobal(logger *zap.SugaredLogger, cfg *Config, writers io.Closer, async *asyncQueue) {
+	old := getState()
+	globalLogger := logger
+	if cfg.ShowLine {
+		globalLogger = logger.Desugar().WithOptions(zap.AddCallerSkip(1)).Sugar()
+	}
+	currentState.Store(&loggerState{
+		logger:        globalLogger,
+		showGoroutine: cfg.ShowGoroutine,
+		config:        cfg,
+		writers:       writers,
+		verbosity:     newVerbosity(cfg),
+		async:         async,
+	})
+	if old != nil && old.writers != nil {
+		if err := old.writers.Close(); err != nil {
+			fmt.Fprintf(stderr, "glog: failed to close the writers of the previous logger: %v\n", err)
+		}
+	}
 }
 
 func yamlToStruct(file string, out interface{}) (err error) {
@@ -248,52 +389,54 @@ func yamlToStruct(file string, out interface{}) (err error) {
 	return
 }
 
-func newLogger(cfg *Config) (*zap.SugaredLogger, error) {
+// newLogger builds a logger from cfg and returns the writers it opened.
+// All cores are gated by logLevel, so changing it takes effect immediately.
+func newLogger(cfg *Config, logLevel zapcore.LevelEnabler, queue *asyncQueue) (*zap.SugaredLogger, writerSet, error) {
+	if len(cfg.Outputs) > 0 {
+		return newOutputsLogger(cfg, logLevel, queue)
+	}
+
 	// If high performance mode is enabled, use optimized config
 	if cfg.HighPerformance {
-		return newHighPerformanceLogger(cfg)
+		return newHighPerformanceLogger(cfg, logLevel, queue)
 	}
 
-	// Parse log level
-	logLevel := parseLogLevel(cfg.LogLevel)
-
 	path := cfg.Path + cfg.Directory
-	if err := mkdir(path); err != nil {
-		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	if err := mkdirLogs(path, cfg); err != nil {
+		return nil, nil, fmt.Errorf("failed to create log directory: %w", err)
 	}
 
 	// Build cores based on config
+	var writers writerSet
 	var cores []zapcore.Core
 	if cfg.SeparateLevels {
 		// Separate log levels to different files (default behavior, backward compatible)
 		debugLevel := zap.LevelEnablerFunc(func(level zapcore.Level) bool {
-			return level == zap.DebugLevel && logLevel <= level
+			return level == zap.DebugLevel && logLevel.Enabled(level)
 		})
 		infoLevel := zap.LevelEnablerFunc(func(level zapcore.Level) bool {
-			return level == zap.InfoLevel && logLevel <= level
+			return level == zap.InfoLevel && logLevel.Enabled(level)
 		})
 		warnLevel := zap.LevelEnablerFunc(func(level zapcore.Level) bool {
-			return level == zap.WarnLevel && logLevel <= level
+			return level == zap.WarnLevel && logLevel.Enabled(level)
 		})
 		errorLevel := zap.LevelEnablerFunc(func(level zapcore.Level) bool {
-			return level == zap.ErrorLevel && logLevel <= level
+			return level == zap.ErrorLevel && logLevel.Enabled(level)
 		})
 		panicLevel := zap.LevelEnablerFunc(func(level zapcore.Level) bool {
-			return level >= zap.DPanicLevel && logLevel <= level
+			return level >= zap.DPanicLevel && logLevel.Enabled(level)
 		})
 
 		cores = []zapcore.Core{
-			getEncoderCore(path+FileDebug, debugLevel, cfg),
-			getEncoderCore(path+FileInfo, infoLevel, cfg),
-			getEncoderCore(path+FileWarn, warnLevel, cfg),
-			getEncoderCore(path+FileError, errorLevel, cfg),
-			getEncoderCore(path+FilePanic, panicLevel, cfg),
+			getEncoderCore(path+FileDebug, debugLevel, cfg, &writers, queue),
+			getEncoderCore(path+FileInfo, infoLevel, cfg, &writers, queue),
+			getEncoderCore(path+FileWarn, warnLevel, cfg, &writers, queue),
+			getEncoderCore(path+FileError, errorLevel, cfg, &writers, queue),
+			getEncoderCore(path+FilePanic, panicLevel, cfg, &writers, queue),
 		}
 	} else {
 		// Use a single core writing all logs to one file
-		writer := getWriteSyncer(path+"/app.log", cfg)
-		core := zapcore.NewCore(getEncoder(cfg), writer, logLevel)
-		cores = []zapcore.Core{core}
+		cores = []zapcore.Core{getEncoderCore(path+"/app.log", logLevel, cfg, &writers, queue)}
 	}
 
 	logger := zap.New(zapcore.NewTee(cores...))
@@ -304,32 +447,32 @@ func newLogger(cfg *Config) (*zap.SugaredLogger, error) {
 
 	sl := logger.Sugar()
 
-	panicRedirect(path + FileStderr)
-	return sl, nil
+	if !cfg.StderrOnly {
+		panicRedirect(path + FileStderr)
+	}
+	return sl, writers, nil
 }
 
 // newHighPerformanceLogger creates a logger optimized for performance
-func newHighPerformanceLogger(cfg *Config) (*zap.SugaredLogger, error) {
+func newHighPerformanceLogger(cfg *Config, logLevel zapcore.LevelEnabler, queue *asyncQueue) (*zap.SugaredLogger, writerSet, error) {
 	path := cfg.Path + cfg.Directory
-	if err := mkdir(path); err != nil {
-		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	if err := mkdirLogs(path, cfg); err != nil {
+		return nil, nil, fmt.Errorf("failed to create log directory: %w", err)
 	}
 
-	// Respect configured log level instead of hardcoding DebugLevel
-	logLevel := parseLogLevel(cfg.LogLevel)
-
 	// Use a single core writing all logs to one file
-	writer := getWriteSyncer(path+"/app.log", cfg)
-	core := zapcore.NewCore(getEncoder(cfg), writer, logLevel)
-	logger := zap.New(core)
+	var writers writerSet
+	logger := zap.New(getEncoderCore(path+"/app.log", logLevel, cfg, &writers, queue))
 
 	// High performance mode disables some features:
 	// - No caller info for better performance
 	// - No explicit Sync() call to reduce overhead
 
 	sl := logger.Sugar()
-	panicRedirect(path + FileStderr)
-	return sl, nil
+	if !cfg.StderrOnly {
+		panicRedirect(path + FileStderr)
+	}
+	return sl, writers, nil
 }
 
 func mkdir(path string) error {
@@ -342,12 +485,46 @@ func mkdir(path string) error {
 	return nil
 }
 
-func getEncoderCore(filename string, level zapcore.LevelEnabler, cfg *Config) (core zapcore.Core) {
-	writer := getWriteSyncer(filename, cfg)
-	return zapcore.NewCore(getEncoder(cfg), writer, level)
+// mkdirLogs creates the log directory unless cfg writes no log files.
+func mkdirLogs(path string, cfg *Config) error {
+	if cfg.StderrOnly {
+		return nil
+	}
+	return mkdir(path)
+}
+
+// getEncoderCore writes to a rotating file and to the console destinations
+// of cfg. The file never gets color codes; console destinations use the
+// console_* encoder settings and only get colors when they are terminals.
+func getEncoderCore(filename string, level zapcore.LevelEnabler, cfg *Config, writers *writerSet, queue *asyncQueue) (core zapcore.Core) {
+	var cores []zapcore.Core
+	fileCfg := destinationConfig(cfg, false, false, "", "")
+	fileWriter := getWriteSyncer(filename, cfg, writers)
+
+	for _, c := range consoleDestinations(cfg) {
+		consoleCfg := destinationConfig(cfg, true, c.tty, "", "")
+		if fileWriter != nil && sameEncoding(consoleCfg, fileCfg) {
+			// Same output format: encode once for both destinations.
+			fileWriter = zapcore.NewMultiWriteSyncer(c.writer, fileWriter)
+			continue
+		}
+		cores = append(cores, newCore(getEncoder(consoleCfg), c.writer, level, queue))
+	}
+	if fileWriter != nil {
+		cores = append([]zapcore.Core{newCore(getEncoder(fileCfg), fileWriter, level, queue)}, cores...)
+	}
+	if len(cores) == 1 {
+		return cores[0]
+	}
+	return zapcore.NewTee(cores...)
 }
 
-func getWriteSyncer(filename string, cfg *Config) zapcore.WriteSyncer {
+// getWriteSyncer opens a rotating file writer and records it in writers.
+// With StderrOnly no file is opened and nil is returned.
+func getWriteSyncer(filename string, cfg *Config, writers *writerSet) zapcore.WriteSyncer {
+	if cfg.StderrOnly {
+		return nil
+	}
 	hook := &lumberjack.Logger{
 		Filename:   filename,
 		MaxSize:    cfg.Segment.MaxSize,
@@ -356,9 +533,7 @@ func getWriteSyncer(filename string, cfg *Config) zapcore.WriteSyncer {
 		Compress:   cfg.Segment.Compress,
 		LocalTime:  true,
 	}
-	if cfg.LogStdout {
-		return zapcore.NewMultiWriteSyncer(zapcore.AddSync(os.Stdout), zapcore.AddSync(hook))
-	}
+	*writers = append(*writers, hook)
 	return zapcore.AddSync(hook)
 }
 
@@ -409,23 +584,31 @@ func customTimeEncoder(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
 
 // parseLogLevel parses the log level from string
 func parseLogLevel(levelStr string) zapcore.Level {
+	level, err := parseLevel(levelStr)
+	if err != nil {
+		// Default to info level
+		return zap.InfoLevel
+	}
+	return level
+}
+
+// parseLevel parses a level name like parseLogLevel but rejects unknown names.
+func parseLevel(levelStr string) (zapcore.Level, error) {
 	switch strings.ToLower(levelStr) {
 	case "debug":
-		return zap.DebugLevel
+		return zap.DebugLevel, nil
 	case "info":
-		return zap.InfoLevel
+		return zap.InfoLevel, nil
 	case "warn", "warning":
-		return zap.WarnLevel
+		return zap.WarnLevel, nil
 	case "error":
-		return zap.ErrorLevel
+		return zap.ErrorLevel, nil
 	case "panic":
-		return zap.PanicLevel
+		return zap.PanicLevel, nil
 	case "fatal":
-		return zap.FatalLevel
-	default:
-		// Default to info level
-		return zap.InfoLevel
+		return zap.FatalLevel, nil
 	}
+	return zap.InfoLevel, fmt.Errorf("unknown level %q (want debug, info, warn, error, panic or fatal)", levelStr)
 }
 
 func Debug(args ...interface{}) {
@@ -589,6 +772,17 @@ func (l *Logger) Printf(template string, args ...interface{}) {
 	l.Infof(template, args...)
 }
 
+// Close flushes and closes the log files and outputs opened for l. Logging
+// to l afterwards is not supported. Closing a logger built with AsGlobal (or
+// New(..., true)) closes the writers of the package-level functions too;
+// they are otherwise closed when Init installs the next global logger.
+func (l *Logger) Close() error {
+	if l == nil || l.writers == nil {
+		return nil
+	}
+	return l.writers.Close()
+}
+
 // Flush flushes any buffered log entries.
 func Flush() error {
 	if s := getState(); s != nil && s.logger != nil {
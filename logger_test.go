@@ -1381,3 +1381,136 @@ func TestNewErrorWithSetGlobal(t *testing.T) {
 		t.Error("New(true) with error should NOT change the global logger, but it did")
 	}
 }
+
+// --- Tests for programmatic configuration ---
+
+func TestInitWithConfig(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "glog_test_init_with_config")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	cfg := &Config{
+		Encoder:        "console",
+		Directory:      tempDir,
+		EncodeLevel:    CapitalLevelEncoder,
+		SeparateLevels: true,
+	}
+	if err := InitWithConfig(cfg); err != nil {
+		t.Fatalf("InitWithConfig failed: %v", err)
+	}
+
+	Info("init_with_config_message")
+	Flush() //nolint:errcheck
+
+	checkLogFile(t, filepath.Join(tempDir, FileInfo), "INFO", "init_with_config_message")
+
+	// The caller's Config must not be modified by setDefaults.
+	if cfg.StacktraceKey != "" {
+		t.Errorf("Expected caller's config to stay untouched, got StacktraceKey=%q", cfg.StacktraceKey)
+	}
+}
+
+func TestInitWithConfigNil(t *testing.T) {
+	stateBefore := getState()
+	if err := InitWithConfig(nil); err == nil {
+		t.Error("Expected an error for nil config, got nil")
+	}
+	if getState() != stateBefore {
+		t.Error("InitWithConfig(nil) should NOT change the global logger, but it did")
+	}
+}
+
+func TestNewFromConfig(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "glog_test_new_from_config")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	stateBefore := getState()
+
+	logger, err := NewFromConfig(&Config{Encoder: "json", Directory: tempDir})
+	if err != nil {
+		t.Fatalf("NewFromConfig failed: %v", err)
+	}
+	if getState() != stateBefore {
+		t.Error("NewFromConfig() without setGlobal should NOT change the global logger, but it did")
+	}
+
+	logger.Info("new_from_config_message")
+
+	// SeparateLevels is false in a zero Config, so everything goes to app.log.
+	checkLogFile(t, filepath.Join(tempDir, "app.log"), `"level":"info"`, "new_from_config_message")
+}
+
+func TestNewFromConfigSetGlobal(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "glog_test_new_from_config_global")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	if _, err := NewFromConfig(&Config{Directory: tempDir}, true); err != nil {
+		t.Fatalf("NewFromConfig(true) failed: %v", err)
+	}
+
+	Info("new_from_config_global_message")
+	Flush() //nolint:errcheck
+
+	checkLogFile(t, filepath.Join(tempDir, "app.log"), "info", "new_from_config_global_message")
+}
+
+func TestNewLoggerFromConfig(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "glog_test_newlogger_from_config")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	logger, err := NewLoggerFromConfig(&Config{Directory: tempDir, SeparateLevels: true})
+	if err != nil {
+		t.Fatalf("NewLoggerFromConfig failed: %v", err)
+	}
+
+	logger.Printf("hello %s", "printf")
+
+	checkLogFile(t, filepath.Join(tempDir, FileInfo), "info", "hello printf")
+
+	if _, err := NewLoggerFromConfig(nil); err == nil {
+		t.Error("Expected an error for nil config, got nil")
+	}
+}
+
+func TestCurrentConfig(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "glog_test_current_config")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	t.Setenv("GLOG_ENCODER", "json")
+	configPath := writeConfig(t, tempDir, "log_level: warn\nlevels:\n  db: debug\n")
+	if err := Init(configPath, tempDir); err != nil {
+		t.Fatalf("Failed to initialize logger: %v", err)
+	}
+
+	cfg := CurrentConfig()
+	if cfg == nil {
+		t.Fatal("CurrentConfig returned nil after Init")
+	}
+	// File values, environment overrides, the directory argument and
+	// defaults are all reflected.
+	if cfg.LogLevel != "warn" || cfg.Encoder != "json" || cfg.Directory != tempDir ||
+		cfg.EncodeLevel != LowercaseLevelEncoder || !cfg.SeparateLevels {
+		t.Errorf("Unexpected effective config: %+v", cfg)
+	}
+
+	// The result is a copy.
+	cfg.LogLevel = "debug"
+	cfg.Levels["db"] = "error"
+	if again := CurrentConfig(); again.LogLevel != "warn" || again.Levels["db"] != "debug" {
+		t.Errorf("Modifying the result of CurrentConfig changed the state: %+v", again)
+	}
+}
@@ -0,0 +1,291 @@
+package glog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/snappy"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Payload formats of loki outputs, set with the "format" option.
+const (
+	// LokiProtobuf sends snappy-compressed protobuf push requests.
+	LokiProtobuf = "protobuf"
+	// LokiJSON sends JSON push requests.
+	LokiJSON = "json"
+)
+
+// lokiPushPath is added to loki URLs without a path.
+const lokiPushPath = "/loki/api/v1/push"
+
+// lokiLabelName matches valid Loki (Prometheus) label names.
+var lokiLabelName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// lokiSink pushes batches of entries to Loki, grouped into streams by
+// their labels, see parseLokiOutput.
+type lokiSink struct {
+	*batcher
+	poster      *httpPoster
+	format      string
+	labels      map[string]string
+	labelFields []string
+	nameLabel   string
+	levelLabel  string
+}
+
+// lokiStream identifies the stream of an entry.
+type lokiStream struct {
+	// key is the labels in Prometheus format, e.g. {app="api", logger="db"}.
+	key    string
+	labels map[string]string
+}
+
+func newLokiSink(out OutputConfig) (zap.Sink, error) {
+	s, batch, err := parseLokiOutput(out)
+	if err != nil {
+		return nil, err
+	}
+	s.batcher = newBatcher(batch, s.sendBatch, stderrReporter("loki", s.poster.url))
+	return s, nil
+}
+
+// parseLokiOutput reads a loki output. URL is the Loki base or push URL;
+// besides the batch and retry options (see batchOptions and newHTTPPoster)
+// it accepts:
+//
+//   - format: protobuf (default) or json
+//   - labels: static stream labels such as "app=api,env=prod", by default
+//     job set to the program name
+//   - label_fields: fields turned into stream labels, e.g. "service,region"
+//   - name_label: the label of the logger name (see Named), default logger
+//   - level_label: the label of the level, none by default
+//
+// Use Headers for the tenant (X-Scope-OrgID) or authentication.
+func parseLokiOutput(out OutputConfig) (*lokiSink, batchConfig, error) {
+	if out.URL == "" {
+		return nil, batchConfig{}, fmt.Errorf("loki output requires a url")
+	}
+	u, err := url.Parse(out.URL)
+	if err != nil {
+		return nil, batchConfig{}, fmt.Errorf("invalid loki url: %w", err)
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = lokiPushPath
+		out.URL = u.String()
+	}
+
+	opts := newOutputOptions(out.Options)
+	s := &lokiSink{
+		poster:      newHTTPPoster(out, opts),
+		format:      opts.stringOpt("format", LokiProtobuf, LokiProtobuf, LokiJSON),
+		labels:      opts.mapOpt("labels"),
+		labelFields: opts.listOpt("label_fields"),
+		nameLabel:   opts.stringOpt("name_label", "logger"),
+		levelLabel:  opts.stringOpt("level_label", ""),
+	}
+	batch := batchOptions(opts)
+
+	names := make([]string, 0, len(s.labels))
+	for name := range s.labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !lokiLabelName.MatchString(name) {
+			opts.invalid("labels", "invalid label name %q", name)
+		}
+	}
+	if s.nameLabel != "" && !lokiLabelName.MatchString(s.nameLabel) {
+		opts.invalid("name_label", "invalid label name %q", s.nameLabel)
+	}
+	if s.levelLabel != "" && !lokiLabelName.MatchString(s.levelLabel) {
+		opts.invalid("level_label", "invalid label name %q", s.levelLabel)
+	}
+	if len(s.labels) == 0 {
+		s.labels = map[string]string{"job": filepath.Base(os.Args[0])}
+	}
+	return s, batch, opts.err()
+}
+
+// WriteEntry queues ent with the stream labels derived from it.
+func (s *lokiSink) WriteEntry(ent zapcore.Entry, fields []zapcore.Field, p []byte) error {
+	labels := make(map[string]string, len(s.labels)+2)
+	for name, value := range s.labels {
+		labels[name] = value
+	}
+	if s.nameLabel != "" && ent.LoggerName != "" {
+		labels[s.nameLabel] = ent.LoggerName
+	}
+	if s.levelLabel != "" {
+		labels[s.levelLabel] = ent.Level.String()
+	}
+	if len(s.labelFields) > 0 {
+		enc := zapcore.NewMapObjectEncoder()
+		for _, f := range fields {
+			for _, name := range s.labelFields {
+				if f.Key == name {
+					f.AddTo(enc)
+				}
+			}
+		}
+		for key, value := range enc.Fields {
+			labels[lokiSanitizeLabel(key)] = fmt.Sprint(value)
+		}
+	}
+
+	return s.add(batchItem{
+		ent:   ent,
+		data:  append([]byte(nil), bytes.TrimRight(p, "\n")...),
+		extra: lokiStream{key: lokiFormatLabels(labels), labels: labels},
+	})
+}
+
+func (s *lokiSink) Write(p []byte) (int, error) {
+	if err := s.WriteEntry(zapcore.Entry{Level: zapcore.InfoLevel, Time: time.Now()}, nil, p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Sync sends the pending entries and returns the last failure, if any.
+func (s *lokiSink) Sync() error {
+	return s.flush()
+}
+
+func (s *lokiSink) Close() error {
+	err := s.close()
+	s.poster.close()
+	return err
+}
+
+// lokiSanitizeLabel turns a field key into a valid label name.
+func lokiSanitizeLabel(name string) string {
+	b := []byte(name)
+	for i, c := range b {
+		if !(c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || i > 0 && c >= '0' && c <= '9') {
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
+
+// lokiFormatLabels formats labels sorted by name, e.g. {a="x", b="y"}.
+func lokiFormatLabels(labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(name)
+		b.WriteByte('=')
+		b.WriteString(strconv.Quote(labels[name]))
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// lokiGroup is a stream with its entries in a push request.
+type lokiGroup struct {
+	stream lokiStream
+	items  []batchItem
+}
+
+// groupStreams groups items by stream, in order of first appearance, with
+// the entries of every stream sorted by time as Loki expects.
+func groupStreams(items []batchItem) []*lokiGroup {
+	var groups []*lokiGroup
+	byKey := make(map[string]*lokiGroup)
+	for _, it := range items {
+		stream := it.extra.(lokiStream)
+		g, ok := byKey[stream.key]
+		if !ok {
+			g = &lokiGroup{stream: stream}
+			byKey[stream.key] = g
+			groups = append(groups, g)
+		}
+		g.items = append(g.items, it)
+	}
+	for _, g := range groups {
+		sort.SliceStable(g.items, func(i, j int) bool { return g.items[i].ent.Time.Before(g.items[j].ent.Time) })
+	}
+	return groups
+}
+
+func (s *lokiSink) sendBatch(items []batchItem) error {
+	groups := groupStreams(items)
+	if s.format == LokiJSON {
+		return s.poster.post("application/json", lokiJSONPush(groups))
+	}
+	return s.poster.post("application/x-protobuf", snappy.Encode(nil, lokiProtoPush(groups)))
+}
+
+// lokiJSONPush encodes a push request in the JSON format:
+//
+//	{"streams":[{"stream":{"app":"api"},"values":[["<unix ns>","line"]]}]}
+func lokiJSONPush(groups []*lokiGroup) []byte {
+	type stream struct {
+		Stream map[string]string `json:"stream"`
+		Values [][2]string       `json:"values"`
+	}
+	req := struct {
+		Streams []stream `json:"streams"`
+	}{Streams: make([]stream, 0, len(groups))}
+	for _, g := range groups {
+		st := stream{Stream: g.stream.labels, Values: make([][2]string, 0, len(g.items))}
+		for _, it := range g.items {
+			st.Values = append(st.Values, [2]string{strconv.FormatInt(it.ent.Time.UnixNano(), 10), string(it.data)})
+		}
+		req.Streams = append(req.Streams, st)
+	}
+	body, _ := json.Marshal(req)
+	return body
+}
+
+// lokiProtoPush encodes a logproto.PushRequest:
+//
+//	message PushRequest { repeated StreamAdapter streams = 1; }
+//	message StreamAdapter { string labels = 1; repeated EntryAdapter entries = 2; }
+//	message EntryAdapter { google.protobuf.Timestamp timestamp = 1; string line = 2; }
+func lokiProtoPush(groups []*lokiGroup) []byte {
+	var req, stream, entry, ts []byte
+	for _, g := range groups {
+		stream = protowire.AppendTag(stream[:0], 1, protowire.BytesType)
+		stream = protowire.AppendString(stream, g.stream.key)
+		for _, it := range g.items {
+			ts = protowire.AppendTag(ts[:0], 1, protowire.VarintType)
+			ts = protowire.AppendVarint(ts, uint64(it.ent.Time.Unix()))
+			ts = protowire.AppendTag(ts, 2, protowire.VarintType)
+			ts = protowire.AppendVarint(ts, uint64(it.ent.Time.Nanosecond()))
+
+			entry = protowire.AppendTag(entry[:0], 1, protowire.BytesType)
+			entry = protowire.AppendBytes(entry, ts)
+			entry = protowire.AppendTag(entry, 2, protowire.BytesType)
+			entry = protowire.AppendBytes(entry, it.data)
+
+			stream = protowire.AppendTag(stream, 2, protowire.BytesType)
+			stream = protowire.AppendBytes(stream, entry)
+		}
+		req = protowire.AppendTag(req, 1, protowire.BytesType)
+		req = protowire.AppendBytes(req, stream)
+	}
+	return req
+}
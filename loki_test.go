@@ -0,0 +1,208 @@
+package glog
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/golang/snappy"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// lokiEntry is a decoded entry of a push request.
+type lokiEntry struct {
+	labels string
+	nanos  int64
+	line   string
+}
+
+// decodeLokiProto decodes a logproto.PushRequest.
+func decodeLokiProto(t *testing.T, b []byte) []lokiEntry {
+	t.Helper()
+	var entries []lokiEntry
+	fields := func(b []byte, fn func(num protowire.Number, v []byte, n uint64)) {
+		for len(b) > 0 {
+			num, typ, l := protowire.ConsumeTag(b)
+			if l < 0 {
+				t.Fatalf("Invalid tag: %v", protowire.ParseError(l))
+			}
+			b = b[l:]
+			switch typ {
+			case protowire.BytesType:
+				v, l := protowire.ConsumeBytes(b)
+				if l < 0 {
+					t.Fatalf("Invalid bytes: %v", protowire.ParseError(l))
+				}
+				fn(num, v, 0)
+				b = b[l:]
+			case protowire.VarintType:
+				v, l := protowire.ConsumeVarint(b)
+				if l < 0 {
+					t.Fatalf("Invalid varint: %v", protowire.ParseError(l))
+				}
+				fn(num, nil, v)
+				b = b[l:]
+			default:
+				t.Fatalf("Unexpected wire type %d", typ)
+			}
+		}
+	}
+	fields(b, func(_ protowire.Number, stream []byte, _ uint64) {
+		var labels string
+		fields(stream, func(num protowire.Number, v []byte, _ uint64) {
+			if num == 1 {
+				labels = string(v)
+				return
+			}
+			e := lokiEntry{labels: labels}
+			fields(v, func(num protowire.Number, v []byte, _ uint64) {
+				if num == 2 {
+					e.line = string(v)
+					return
+				}
+				fields(v, func(num protowire.Number, _ []byte, n uint64) {
+					if num == 1 {
+						e.nanos += int64(n) * 1e9
+					} else {
+						e.nanos += int64(n)
+					}
+				})
+			})
+			entries = append(entries, e)
+		})
+	})
+	return entries
+}
+
+func TestLokiOutputProtobuf(t *testing.T) {
+	c := newCollector(t)
+	logger, err := Build(WithConfig(&Config{
+		Encoder: "json",
+		Outputs: []OutputConfig{{
+			Type:    "loki",
+			URL:     c.URL,
+			Headers: map[string]string{"X-Scope-OrgID": "tenant-1"},
+			Options: map[string]string{
+				"labels":       "app=api,env=prod",
+				"label_fields": "region",
+				"batch_delay":  "1h",
+			},
+		}},
+	}))
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	logger.Info("loki_plain")
+	logger.Named("db").Infow("loki_db", "region", "eu-west")
+	logger.Info("loki_plain_again")
+	if err := logger.Sync(); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	c.wait(t, 1)
+
+	req := c.requests[0]
+	if req.URL.Path != lokiPushPath {
+		t.Errorf("Expected push path %s, got %s", lokiPushPath, req.URL.Path)
+	}
+	if got := req.Header.Get("Content-Type"); got != "application/x-protobuf" {
+		t.Errorf("Expected Content-Type application/x-protobuf, got %q", got)
+	}
+	if got := req.Header.Get("X-Scope-OrgID"); got != "tenant-1" {
+		t.Errorf("Expected the tenant header, got %q", got)
+	}
+	body, err := snappy.Decode(nil, []byte(c.Bodies()[0]))
+	if err != nil {
+		t.Fatalf("Body is not snappy: %v", err)
+	}
+
+	entries := decodeLokiProto(t, body)
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 entries, got %+v", entries)
+	}
+	plain := `{app="api", env="prod"}`
+	db := `{app="api", env="prod", logger="db", region="eu-west"}`
+	for i, want := range []struct{ labels, message string }{
+		{plain, "loki_plain"},
+		{plain, "loki_plain_again"},
+		{db, "loki_db"},
+	} {
+		e := entries[i]
+		if e.labels != want.labels || !strings.Contains(e.line, want.message) {
+			t.Errorf("Entry %d: expected %s %s, got %+v", i, want.labels, want.message, e)
+		}
+		if e.nanos == 0 || strings.HasSuffix(e.line, "\n") {
+			t.Errorf("Entry %d: expected a timestamp and no trailing newline, got %+v", i, e)
+		}
+	}
+	if entries[0].nanos > entries[1].nanos {
+		t.Errorf("Entries of a stream should be sorted by time")
+	}
+}
+
+func TestLokiOutputJSON(t *testing.T) {
+	c := newCollector(t)
+	logger, err := Build(WithConfig(&Config{
+		Outputs: []OutputConfig{{
+			Type: "loki",
+			URL:  c.URL + "/custom/push",
+			Options: map[string]string{
+				"format":      "json",
+				"level_label": "level",
+				"name_label":  "component",
+			},
+		}},
+	}))
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	logger.Named("worker").Warn("loki_json")
+	if err := logger.Sync(); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	c.wait(t, 1)
+
+	if got := c.requests[0].URL.Path; got != "/custom/push" {
+		t.Errorf("Expected the configured path to be kept, got %s", got)
+	}
+	var push struct {
+		Streams []struct {
+			Stream map[string]string `json:"stream"`
+			Values [][2]string       `json:"values"`
+		} `json:"streams"`
+	}
+	if err := json.Unmarshal([]byte(c.Bodies()[0]), &push); err != nil {
+		t.Fatalf("Body is not JSON: %v", err)
+	}
+	if len(push.Streams) != 1 || len(push.Streams[0].Values) != 1 {
+		t.Fatalf("Expected one stream with one entry, got %+v", push)
+	}
+	stream := push.Streams[0]
+	if stream.Stream["level"] != "warn" || stream.Stream["component"] != "worker" || stream.Stream["job"] == "" {
+		t.Errorf("Unexpected labels %v", stream.Stream)
+	}
+	if !strings.Contains(stream.Values[0][1], "loki_json") || stream.Values[0][0] == "" {
+		t.Errorf("Unexpected value %q", stream.Values[0])
+	}
+}
+
+func TestLokiOutputOptionErrors(t *testing.T) {
+	out := OutputConfig{Type: "loki", URL: "http://127.0.0.1:1", Options: map[string]string{
+		"format":     "text",
+		"labels":     "app=api,1bad=x",
+		"name_label": "logger-name",
+	}}
+	problems := out.validate("outputs[0]")
+	if len(problems) != 1 {
+		t.Fatalf("Expected one options problem, got %q", problems)
+	}
+	for _, want := range []string{"format: unknown value", `"1bad"`, `"logger-name"`} {
+		if !strings.Contains(problems[0], want) {
+			t.Errorf("Problem should mention %q, got: %s", want, problems[0])
+		}
+	}
+
+	if problems := (OutputConfig{Type: "loki"}).validate("outputs[0]"); len(problems) != 1 || !strings.Contains(problems[0], "url") {
+		t.Errorf("Expected a missing url problem, got %q", problems)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackman0925/glog"
 	"go.uber.org/zap"
 )
 
@@ -26,6 +27,9 @@ func GinLogger(log *zap.SugaredLogger) gin.HandlerFunc {
 }
 
 // GinLoggerWithConfig returns a request logging middleware with config.
+// Access logs of traced requests carry trace_id, span_id and trace_flags,
+// see glog.TraceFields; register the tracing middleware (e.g. otelgin)
+// before this one so the span is in the request context.
 func GinLoggerWithConfig(log *zap.SugaredLogger, cfg LoggerConfig) gin.HandlerFunc {
 	logger := ensureLogger(log)
 	skip := make(map[string]struct{}, len(cfg.SkipPaths))
@@ -75,6 +79,9 @@ func GinLoggerWithConfig(log *zap.SugaredLogger, cfg LoggerConfig) gin.HandlerFu
 		if errMsg := c.Errors.String(); errMsg != "" {
 			fields = append(fields, "errors", errMsg)
 		}
+		for _, f := range glog.TraceFields(c.Request.Context()) {
+			fields = append(fields, f)
+		}
 
 		switch {
 		case status >= http.StatusInternalServerError:
@@ -117,3 +124,10 @@ func ensureLogger(log *zap.SugaredLogger) *zap.SugaredLogger {
 	}
 	return zap.NewNop().Sugar()
 }
+
+// AdminHandler exposes glog.AdminHandler on a gin router, e.g.
+//
+//	r.Any("/debug/log", ginmw.AdminHandler())
+func AdminHandler() gin.HandlerFunc {
+	return gin.WrapH(glog.AdminHandler())
+}
@@ -7,6 +7,8 @@ import (
 	"testing"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackman0925/glog"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"go.uber.org/zap/zaptest/observer"
@@ -118,6 +120,43 @@ func TestGinLoggerSkipPaths(t *testing.T) {
 	}
 }
 
+func TestGinLoggerTraceFields(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log, observed := newObservedSugaredLogger(zapcore.DebugLevel)
+
+	span := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:     trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+		TraceFlags: trace.FlagsSampled,
+	})
+	r := gin.New()
+	// Stands in for a tracing middleware such as otelgin.
+	r.Use(func(c *gin.Context) {
+		if c.Request.URL.Path == "/traced" {
+			c.Request = c.Request.WithContext(trace.ContextWithSpanContext(c.Request.Context(), span))
+		}
+		c.Next()
+	})
+	r.Use(GinLogger(log))
+	r.GET("/traced", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+	r.GET("/untraced", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/traced", nil))
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/untraced", nil))
+
+	entries := observed.All()
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 log entries, got %d", len(entries))
+	}
+	ctx := entries[0].ContextMap()
+	if ctx["trace_id"] != "4bf92f3577b34da6a3ce929d0e0e4736" || ctx["span_id"] != "00f067aa0ba902b7" || ctx["trace_flags"] != "01" {
+		t.Fatalf("expected trace fields, got %#v", ctx)
+	}
+	if _, ok := entries[1].ContextMap()["trace_id"]; ok {
+		t.Fatalf("expected no trace fields without a span, got %#v", entries[1].ContextMap())
+	}
+}
+
 func TestGinRecovery(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	log, observed := newObservedSugaredLogger(zapcore.DebugLevel)
@@ -172,3 +211,29 @@ func TestNilLoggerDoesNotPanic(t *testing.T) {
 		t.Fatalf("expected 200, got %d", w.Code)
 	}
 }
+
+func TestAdminHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	defer glog.SetLevel(zap.InfoLevel)
+
+	r := gin.New()
+	r.Any("/debug/log", AdminHandler())
+
+	req := httptest.NewRequest(http.MethodPut, "/debug/log", strings.NewReader(`{"level":"debug"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if glog.GetLevel() != zap.DebugLevel {
+		t.Fatalf("expected global level debug, got %s", glog.GetLevel())
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/log", nil))
+	if !strings.Contains(w.Body.String(), `"level":"debug"`) {
+		t.Fatalf("unexpected GET response: %s", w.Body.String())
+	}
+}
@@ -0,0 +1,309 @@
+package glog
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Defaults of NetWriterConfig.
+const (
+	defaultNetBufferSize   = 1 << 20
+	defaultNetMinBackoff   = 100 * time.Millisecond
+	defaultNetMaxBackoff   = 30 * time.Second
+	defaultNetDialTimeout  = 5 * time.Second
+	defaultNetWriteTimeout = 5 * time.Second
+)
+
+// NetWriterConfig configures a NetWriter.
+type NetWriterConfig struct {
+	// Network is tcp, udp, unix (stream) or unixgram, or a variant such as tcp4.
+	Network string
+	// Address is the host:port or socket path.
+	Address string
+	// BufferSize is the maximum number of bytes held while the connection
+	// is down or slow, 1 MB by default. The oldest entries are dropped
+	// when it is exceeded.
+	BufferSize int
+	// MinBackoff and MaxBackoff bound the delay between reconnection
+	// attempts, which doubles after every failure; 100ms and 30s by default.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// DialTimeout and WriteTimeout default to 5s.
+	DialTimeout  time.Duration
+	WriteTimeout time.Duration
+	// OnError is called from the background goroutine when the connection
+	// fails, once per outage. By default errors are printed to stderr.
+	OnError func(error)
+}
+
+// NetWriter is a zapcore.WriteSyncer sending every write as one message to
+// a tcp, udp or unix socket. Writes never block: they are buffered in
+// memory and sent by a background goroutine, which reconnects with
+// exponential backoff when the connection fails.
+//
+// NetWriter is what tcp, udp, unix and unixgram outputs use; it can also be
+// passed to WithWriter. Close it when it is no longer used.
+type NetWriter struct {
+	cfg     NetWriterConfig
+	dropped atomic.Uint64
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	pending [][]byte
+	size    int  // bytes in pending
+	sending bool // the background goroutine is writing taken entries
+	failing bool // the last dial or write failed
+	lastErr error
+	closed  bool
+
+	wake chan struct{}
+	done chan struct{}
+}
+
+// NewNetWriter returns a NetWriter for cfg. It does not connect; the
+// first connection is made in the background.
+func NewNetWriter(cfg NetWriterConfig) (*NetWriter, error) {
+	switch cfg.Network {
+	case "tcp", "tcp4", "tcp6", "udp", "udp4", "udp6", "unix", "unixgram":
+	default:
+		return nil, fmt.Errorf("unknown network %q (want tcp, udp, unix or unixgram)", cfg.Network)
+	}
+	if cfg.Address == "" {
+		return nil, fmt.Errorf("%s writer requires an address", cfg.Network)
+	}
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = defaultNetBufferSize
+	}
+	if cfg.MinBackoff <= 0 {
+		cfg.MinBackoff = defaultNetMinBackoff
+	}
+	if cfg.MaxBackoff < cfg.MinBackoff {
+		cfg.MaxBackoff = max(defaultNetMaxBackoff, cfg.MinBackoff)
+	}
+	if cfg.DialTimeout <= 0 {
+		cfg.DialTimeout = defaultNetDialTimeout
+	}
+	if cfg.WriteTimeout <= 0 {
+		cfg.WriteTimeout = defaultNetWriteTimeout
+	}
+	if cfg.OnError == nil {
+		cfg.OnError = func(err error) {
+			fmt.Fprintf(os.Stderr, "glog: %s writer %s: %v\n", cfg.Network, cfg.Address, err)
+		}
+	}
+
+	w := &NetWriter{
+		cfg:  cfg,
+		wake: make(chan struct{}, 1),
+		done: make(chan struct{}),
+	}
+	w.cond = sync.NewCond(&w.mu)
+	go w.run()
+	return w, nil
+}
+
+// Write buffers a copy of p as one message. It only fails once w is closed.
+func (w *NetWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return 0, errSinkClosed
+	}
+	if len(p) > w.cfg.BufferSize {
+		w.mu.Unlock()
+		w.dropped.Add(1)
+		return len(p), nil
+	}
+	for w.size+len(p) > w.cfg.BufferSize {
+		w.size -= len(w.pending[0])
+		w.pending[0] = nil
+		w.pending = w.pending[1:]
+		w.dropped.Add(1)
+	}
+	w.pending = append(w.pending, append([]byte(nil), p...))
+	w.size += len(p)
+	// Signal while holding mu, so Close cannot close wake in between.
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+	w.mu.Unlock()
+	return len(p), nil
+}
+
+// Sync waits until the buffered messages have been sent. It returns the
+// connection error instead if the connection is down.
+func (w *NetWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for (len(w.pending) > 0 || w.sending) && !w.failing && !w.closed {
+		w.cond.Wait()
+	}
+	if w.failing && len(w.pending) > 0 {
+		return w.lastErr
+	}
+	return nil
+}
+
+// Close sends what is still buffered if the connection is up, then closes
+// it and stops the background goroutine.
+func (w *NetWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	w.cond.Broadcast()
+	w.mu.Unlock()
+
+	close(w.wake)
+	<-w.done
+	return nil
+}
+
+// Dropped returns the number of messages discarded because the buffer
+// was full.
+func (w *NetWriter) Dropped() uint64 {
+	return w.dropped.Load()
+}
+
+// run connects and sends the buffered messages until w is closed.
+func (w *NetWriter) run() {
+	defer close(w.done)
+
+	var conn net.Conn
+	defer func() {
+		if conn != nil {
+			conn.Close()
+		}
+	}()
+	backoff := w.cfg.MinBackoff
+
+	for {
+		w.mu.Lock()
+		closed := w.closed
+		batch := w.pending
+		w.pending, w.size = nil, 0
+		w.sending = len(batch) > 0
+		w.mu.Unlock()
+
+		if len(batch) == 0 {
+			if closed {
+				return
+			}
+			<-w.wake
+			continue
+		}
+
+		if conn == nil && !closed {
+			var err error
+			if conn, err = net.DialTimeout(w.cfg.Network, w.cfg.Address, w.cfg.DialTimeout); err != nil {
+				w.requeue(batch, err)
+				if !w.sleep(backoff) {
+					return
+				}
+				backoff = min(backoff*2, w.cfg.MaxBackoff)
+				continue
+			}
+			backoff = w.cfg.MinBackoff
+		}
+		if conn == nil {
+			// Closed while disconnected: the remaining messages are lost.
+			return
+		}
+
+		for i, msg := range batch {
+			conn.SetWriteDeadline(time.Now().Add(w.cfg.WriteTimeout)) //nolint:errcheck
+			if _, err := conn.Write(msg); err != nil {
+				conn.Close()
+				conn = nil
+				w.requeue(batch[i:], err)
+				break
+			}
+		}
+		if conn != nil {
+			w.mu.Lock()
+			w.sending = false
+			w.failing = false
+			w.lastErr = nil
+			w.cond.Broadcast()
+			w.mu.Unlock()
+		}
+	}
+}
+
+// requeue puts unsent messages back in front of the buffer, within its
+// limit, and reports err unless the connection was already failing.
+func (w *NetWriter) requeue(batch [][]byte, err error) {
+	w.mu.Lock()
+	size := w.size
+	for _, msg := range batch {
+		size += len(msg)
+	}
+	for size > w.cfg.BufferSize {
+		size -= len(batch[0])
+		batch = batch[1:]
+		w.dropped.Add(1)
+	}
+	w.pending = append(batch, w.pending...)
+	w.size = size
+	w.sending = false
+	report := !w.failing
+	w.failing = true
+	w.lastErr = err
+	w.cond.Broadcast()
+	w.mu.Unlock()
+
+	if report {
+		w.cfg.OnError(err)
+	}
+}
+
+// sleep waits for d and reports false if w was closed meanwhile.
+func (w *NetWriter) sleep(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.C:
+			return true
+		case _, ok := <-w.wake:
+			if !ok {
+				return false
+			}
+		}
+	}
+}
+
+// newNetSink creates the NetWriter of a tcp, udp, unix or unixgram output.
+func newNetSink(out OutputConfig) (zap.Sink, error) {
+	cfg, err := netWriterConfig(out)
+	if err != nil {
+		return nil, err
+	}
+	return NewNetWriter(cfg)
+}
+
+// netWriterConfig returns the NetWriterConfig of a network output, with the
+// options buffer_size (bytes), min_backoff and max_backoff (durations).
+func netWriterConfig(out OutputConfig) (NetWriterConfig, error) {
+	if out.Address == "" {
+		return NetWriterConfig{}, fmt.Errorf("%s output requires an address", out.Type)
+	}
+	opts := newOutputOptions(out.Options)
+	cfg := NetWriterConfig{
+		Network:    out.Type,
+		Address:    out.Address,
+		BufferSize: opts.intOpt("buffer_size", 0),
+		MinBackoff: opts.durationOpt("min_backoff", 0),
+		MaxBackoff: opts.durationOpt("max_backoff", 0),
+	}
+	return cfg, opts.err()
+}
@@ -0,0 +1,199 @@
+package glog
+
+import (
+	"bufio"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// freeAddr returns a local tcp address nobody listens on.
+func freeAddr(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+	return addr
+}
+
+func TestNetWriterReconnectsAndSendsBuffered(t *testing.T) {
+	addr := freeAddr(t)
+	errs := make(chan error, 10)
+	w, err := NewNetWriter(NetWriterConfig{
+		Network:    "tcp",
+		Address:    addr,
+		MinBackoff: 10 * time.Millisecond,
+		MaxBackoff: 20 * time.Millisecond,
+		OnError:    func(err error) { errs <- err },
+	})
+	if err != nil {
+		t.Fatalf("NewNetWriter failed: %v", err)
+	}
+	defer w.Close()
+
+	// Nobody listens yet: writes are buffered and do not block.
+	w.Write([]byte("buffered_1\n")) //nolint:errcheck
+	w.Write([]byte("buffered_2\n")) //nolint:errcheck
+	select {
+	case <-errs:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the connection error")
+	}
+	if err := w.Sync(); err == nil {
+		t.Error("Sync should report the connection error while disconnected")
+	}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		t.Fatalf("Listen on %s failed: %v", addr, err)
+	}
+	defer ln.Close()
+	lines := make(chan string, 10)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		scanner := bufio.NewScanner(conn)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+
+	for _, want := range []string{"buffered_1", "buffered_2"} {
+		select {
+		case line := <-lines:
+			if line != want {
+				t.Errorf("Expected %q, got %q", want, line)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Timed out waiting for %q", want)
+		}
+	}
+
+	w.Write([]byte("connected\n")) //nolint:errcheck
+	if err := w.Sync(); err != nil {
+		t.Errorf("Sync failed after reconnecting: %v", err)
+	}
+	select {
+	case line := <-lines:
+		if line != "connected" {
+			t.Errorf("Expected %q, got %q", "connected", line)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the entry written after reconnecting")
+	}
+	if len(errs) != 0 {
+		t.Errorf("The outage should be reported once, got %d more errors", len(errs))
+	}
+}
+
+func TestNetWriterBufferLimit(t *testing.T) {
+	errs := make(chan error, 10)
+	w, err := NewNetWriter(NetWriterConfig{
+		Network:    "tcp",
+		Address:    freeAddr(t),
+		BufferSize: 10,
+		MinBackoff: time.Hour,
+		OnError:    func(err error) { errs <- err },
+	})
+	if err != nil {
+		t.Fatalf("NewNetWriter failed: %v", err)
+	}
+
+	w.Write([]byte("msg1")) //nolint:errcheck
+	select {
+	case <-errs:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the connection error")
+	}
+
+	// The writer now waits an hour before reconnecting; only the two newest
+	// messages fit in the buffer.
+	for _, msg := range []string{"msg2", "msg3", "msg4", "msg5"} {
+		if _, err := w.Write([]byte(msg)); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	if w.Dropped() != 3 {
+		t.Errorf("Expected 3 dropped messages, got %d", w.Dropped())
+	}
+	w.mu.Lock()
+	kept := string(append(append([]byte(nil), w.pending[0]...), w.pending[1]...))
+	w.mu.Unlock()
+	if kept != "msg4msg5" {
+		t.Errorf("Expected the newest messages to be kept, got %q", kept)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		w.Close()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close should interrupt the reconnection backoff")
+	}
+	if _, err := w.Write([]byte("late")); err == nil {
+		t.Error("Expected an error when writing to a closed writer, got nil")
+	}
+}
+
+func TestUnixgramOutput(t *testing.T) {
+	dir, err := os.MkdirTemp("", "glog_test_unixgram")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "agent.sock")
+	pc, err := net.ListenPacket("unixgram", path)
+	if err != nil {
+		t.Skipf("unixgram sockets are not supported: %v", err)
+	}
+	defer pc.Close()
+
+	logger, err := Build(WithConfig(&Config{
+		Encoder: "json",
+		Outputs: []OutputConfig{{Type: "unixgram", Address: path, Options: map[string]string{"buffer_size": "4096"}}},
+	}))
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	logger.Info("unixgram_message")
+	if err := logger.Sync(); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+
+	if msg := readDatagram(t, pc); !strings.Contains(msg, `"message":"unixgram_message"`) {
+		t.Errorf("Unexpected datagram %q", msg)
+	}
+}
+
+func TestNetOutputOptionErrors(t *testing.T) {
+	out := OutputConfig{Type: "tcp", Address: "127.0.0.1:1", Options: map[string]string{
+		"buffer_size": "-1",
+		"max_backoff": "soon",
+		"retries":     "3",
+	}}
+	problems := out.validate("outputs[0]")
+	if len(problems) != 1 {
+		t.Fatalf("Expected one options problem, got %q", problems)
+	}
+	for _, want := range []string{"buffer_size", "max_backoff", "retries: unknown option"} {
+		if !strings.Contains(problems[0], want) {
+			t.Errorf("Problem should mention %q, got: %s", want, problems[0])
+		}
+	}
+	if _, err := NewNetWriter(NetWriterConfig{Network: "sctp", Address: "x"}); err == nil {
+		t.Error("Expected an error for an unknown network, got nil")
+	}
+}
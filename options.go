@@ -0,0 +1,267 @@
+package glog
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Option configures how Build constructs a logger.
+type Option func(*buildOptions)
+
+// buildOptions collects everything passed to Build.
+type buildOptions struct {
+	// origin selects the base config; the last WithConfigFile / WithConfig wins.
+	origin    configOrigin
+	cfgPath   string
+	format    string
+	profile   *string
+	cfg       *Config
+	envPrefix *string
+	strict    bool
+	directory *string
+	level     *string
+	encoder   *string
+	writers   []zapcore.WriteSyncer
+	fields    []interface{}
+	global    bool
+}
+
+// configOrigin identifies where Build takes its base config from.
+type configOrigin int
+
+const (
+	originNone configOrigin = iota
+	originFile
+	originConfig
+)
+
+// WithConfigFile loads the base configuration from a YAML, JSON or TOML file,
+// see WithConfigFormat. As with Init, separate_levels defaults to true when it
+// is not set in the file.
+func WithConfigFile(cfgPath string) Option {
+	return func(o *buildOptions) {
+		o.origin, o.cfgPath, o.cfg = originFile, cfgPath, nil
+	}
+}
+
+// WithConfig uses an already populated Config as the base configuration.
+// The Config is copied, so the caller's value is never modified.
+func WithConfig(cfg *Config) Option {
+	return func(o *buildOptions) {
+		o.origin, o.cfgPath, o.cfg = originConfig, "", cfg
+	}
+}
+
+// WithDirectory overrides the log directory of the base configuration.
+func WithDirectory(directory string) Option {
+	return func(o *buildOptions) {
+		o.directory = &directory
+	}
+}
+
+// WithLevel overrides the minimum log level (debug, info, warn, error, panic, fatal).
+func WithLevel(level string) Option {
+	return func(o *buildOptions) {
+		o.level = &level
+	}
+}
+
+// WithEncoder overrides the encoder (console or json).
+func WithEncoder(encoder string) Option {
+	return func(o *buildOptions) {
+		o.encoder = &encoder
+	}
+}
+
+// WithWriter adds an extra destination that receives every enabled entry,
+// encoded with the configured encoder, in addition to the log files.
+func WithWriter(w io.Writer) Option {
+	return func(o *buildOptions) {
+		o.writers = append(o.writers, zapcore.AddSync(w))
+	}
+}
+
+// WithFields attaches key-value pairs to every entry written by the logger.
+func WithFields(keysAndValues ...interface{}) Option {
+	return func(o *buildOptions) {
+		o.fields = append(o.fields, keysAndValues...)
+	}
+}
+
+// AsGlobal also installs the built logger as the global logger used by the
+// package-level functions such as glog.Info.
+func AsGlobal() Option {
+	return func(o *buildOptions) {
+		o.global = true
+	}
+}
+
+// Build creates a logger from the given options.
+//
+// The returned *Logger embeds the underlying *zap.SugaredLogger, which is
+// available as its SugaredLogger field. Without WithConfigFile or WithConfig
+// the logger starts from an empty config with separate_levels enabled.
+//
+// 示例：
+//
+//	logger, err := glog.Build(
+//		glog.WithConfigFile("./logger.yaml"),
+//		glog.WithDirectory("my-app"),
+//		glog.WithLevel("debug"),
+//		glog.WithFields("service", "order"),
+//		glog.AsGlobal(),
+//	)
+func Build(opts ...Option) (*Logger, error) {
+	o := &buildOptions{strict: strictMode.Load()}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	cfg, err := o.config()
+	if err != nil {
+		return nil, err
+	}
+
+	// A global logger shares globalLevel so SetLevel keeps working across
+	// Init and reloads; other loggers get their own level.
+	level := zap.NewAtomicLevelAt(parseLogLevel(cfg.LogLevel))
+	if o.global {
+		level = globalLevel
+	}
+
+	// With per-component levels the cores accept the lowest configured level
+	// and componentCore filters entries by logger name.
+	var enabler zapcore.LevelEnabler = level
+	components := newComponentLevels(cfg, level)
+	if components != nil {
+		enabler = components
+	}
+
+	// With Async the cores encode entries and queue them for the
+	// background goroutine of async.
+	var async *asyncQueue
+	if cfg.Async.Enabled {
+		async = newAsyncQueue(cfg.Async)
+	}
+
+	logger, writers, err := newLogger(cfg, enabler, async)
+	if err != nil {
+		if async != nil {
+			async.Close() //nolint:errcheck
+		}
+		return nil, err
+	}
+
+	if len(o.writers) > 0 {
+		logger = withWriters(logger, cfg, enabler, o.writers, async)
+	}
+	if async != nil {
+		// Drain the queue before the writers it writes to are closed.
+		writers = append(writerSet{async}, writers...)
+	}
+	if components != nil {
+		logger = withComponentLevels(logger, components)
+	}
+	if len(o.fields) > 0 {
+		logger = logger.With(o.fields...)
+	}
+
+	closer := &writerCloser{writers: writers}
+	if o.global {
+		setBaseLevel(parseLogLevel(cfg.LogLevel))
+		storeGlobal(logger, cfg, closer, async)
+		// The handle follows later Init calls and reloads instead of
+		// writing to the writers storeGlobal closes then.
+		logger = followGlobal()
+	}
+
+	return &Logger{SugaredLogger: logger, level: &level, async: async, writers: closer}, nil
+}
+
+// config resolves the effective config. Later sources win:
+//
+//  1. built-in defaults (applied last, only for fields that are still empty)
+//  2. config file or Config
+//  3. environment variables (GLOG_* by default, see SetEnvPrefix)
+//  4. Build options, including the directory argument of Init/New/NewLogger
+//  5. command-line flags registered with RegisterFlags
+//
+// In strict mode unknown keys and Validate problems are returned together
+// as a *ValidationError.
+func (o *buildOptions) config() (*Config, error) {
+	prefix := getEnvPrefix()
+	if o.envPrefix != nil {
+		prefix = *o.envPrefix
+	}
+
+	if o.profile != nil && *o.profile != "" && o.origin != originFile {
+		return nil, fmt.Errorf("profile %q requires a config file, see WithConfigFile", *o.profile)
+	}
+
+	var problems []string
+	cfg := &Config{SeparateLevels: true}
+	switch o.origin {
+	case originFile:
+		unknown, err := readConfigFile(o.cfgPath, o.format, cfg, o.selectedProfile(prefix), o.strict)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse config file: %w", err)
+		}
+		problems = append(problems, unknown...)
+	case originConfig:
+		if o.cfg == nil {
+			return nil, fmt.Errorf("config must not be nil")
+		}
+		cfg = o.cfg.clone()
+	}
+
+	if err := applyEnv(cfg, prefix); err != nil {
+		return nil, fmt.Errorf("failed to apply environment overrides: %w", err)
+	}
+
+	if o.directory != nil {
+		cfg.Directory = *o.directory
+	}
+	if o.level != nil {
+		cfg.LogLevel = *o.level
+	}
+	if o.encoder != nil {
+		cfg.Encoder = *o.encoder
+	}
+
+	// Command-line flags configure the global logger only; other loggers
+	// are built from their own config.
+	if o.global {
+		if err := applyFlags(cfg); err != nil {
+			return nil, fmt.Errorf("failed to apply command-line flags: %w", err)
+		}
+	}
+
+	cfg.setDefaults()
+
+	if o.strict {
+		var verr *ValidationError
+		if err := cfg.Validate(); errors.As(err, &verr) {
+			problems = append(problems, verr.Problems...)
+		}
+		if len(problems) > 0 {
+			return nil, &ValidationError{Problems: problems}
+		}
+	}
+	return cfg, nil
+}
+
+// withWriters tees every enabled entry of logger into the given writers.
+func withWriters(logger *zap.SugaredLogger, cfg *Config, logLevel zapcore.LevelEnabler, writers []zapcore.WriteSyncer, queue *asyncQueue) *zap.SugaredLogger {
+	return logger.Desugar().WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		cores := []zapcore.Core{core}
+		for _, w := range writers {
+			enc := getEncoder(destinationConfig(cfg, false, writerIsTerminal(w), "", ""))
+			cores = append(cores, newCore(enc, w, logLevel, queue))
+		}
+		return zapcore.NewTee(cores...)
+	})).Sugar()
+}
@@ -0,0 +1,134 @@
+package glog
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuildWithConfigFile(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "glog_test_build_file")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	configPath := writeConfig(t, tempDir, baseConsoleConfig)
+
+	stateBefore := getState()
+	logger, err := Build(WithConfigFile(configPath), WithDirectory(tempDir))
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if logger.SugaredLogger == nil {
+		t.Fatal("Build returned a Logger without SugaredLogger")
+	}
+	if getState() != stateBefore {
+		t.Error("Build() without AsGlobal should NOT change the global logger, but it did")
+	}
+
+	logger.Info("build_with_file_message")
+	checkLogFile(t, filepath.Join(tempDir, FileInfo), "INFO", "build_with_file_message")
+}
+
+func TestBuildConfigFileError(t *testing.T) {
+	_, err := Build(WithConfigFile("non_existent_config.yaml"))
+	if err == nil {
+		t.Fatal("Expected an error for a non-existent config file, got nil")
+	}
+	if !strings.Contains(err.Error(), "failed to parse config file") {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestBuildOverrides(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "glog_test_build_overrides")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// The file says console/info, the options say json/debug.
+	configPath := writeConfig(t, tempDir, baseConsoleConfig+"log_level: info\nseparate_levels: false\n")
+
+	logger, err := Build(
+		WithConfigFile(configPath),
+		WithDirectory(tempDir),
+		WithLevel("debug"),
+		WithEncoder("json"),
+	)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	logger.Debug("build_override_debug_message")
+	checkLogFile(t, filepath.Join(tempDir, "app.log"), `"level":"DEBUG"`, `"message":"build_override_debug_message"`)
+}
+
+func TestBuildWithWriterAndFields(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "glog_test_build_writer")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	var buf bytes.Buffer
+	logger, err := Build(
+		WithConfig(&Config{Directory: tempDir, Encoder: "json"}),
+		WithWriter(&buf),
+		WithFields("service", "order"),
+	)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	logger.Info("build_writer_message")
+	logger.Debug("build_writer_filtered_message")
+
+	out := buf.String()
+	if !strings.Contains(out, "build_writer_message") || !strings.Contains(out, `"service":"order"`) {
+		t.Errorf("Extra writer should receive the entry with fields. Got: %s", out)
+	}
+	if strings.Contains(out, "build_writer_filtered_message") {
+		t.Errorf("Extra writer should respect the log level. Got: %s", out)
+	}
+
+	checkLogFile(t, filepath.Join(tempDir, "app.log"), `"service":"order"`, "build_writer_message")
+}
+
+func TestBuildAsGlobal(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "glog_test_build_global")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	if _, err := Build(WithConfig(&Config{Directory: tempDir}), AsGlobal()); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	Info("build_as_global_message")
+	Flush() //nolint:errcheck
+
+	checkLogFile(t, filepath.Join(tempDir, "app.log"), "info", "build_as_global_message")
+}
+
+func TestBuildWithoutConfigSource(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "glog_test_build_default")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	logger, err := Build(WithDirectory(tempDir))
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	logger.Info("build_default_message")
+
+	// Without a config source separate_levels defaults to true, like Init.
+	checkLogFile(t, filepath.Join(tempDir, FileInfo), "info", "build_default_message")
+}
@@ -0,0 +1,528 @@
+package glog
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"sort"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Payload encodings of otlp outputs, set with the "format" option.
+const (
+	// OTLPProtobuf sends binary protobuf export requests.
+	OTLPProtobuf = "protobuf"
+	// OTLPJSON sends export requests in the OTLP JSON encoding.
+	OTLPJSON = "json"
+)
+
+// otlpLogsPath is added to otlp URLs without a path.
+const otlpLogsPath = "/v1/logs"
+
+// otlpDefaultScope is the instrumentation scope of unnamed loggers.
+const otlpDefaultScope = "github.com/jackman0925/glog"
+
+// otlpSink exports batches of entries as OTLP log records over HTTP, see
+// parseOTLPOutput. The output's encoder is not used: records carry the
+// message as body and the fields as attributes.
+type otlpSink struct {
+	*batcher
+	poster   *httpPoster
+	format   string
+	resource []otlpKeyValue
+}
+
+// otlpKeyValue is an attribute. Its value is a string, bool, int64,
+// float64, []byte, []interface{} of such values or []otlpKeyValue.
+type otlpKeyValue struct {
+	key   string
+	value interface{}
+}
+
+// otlpRecord is a LogRecord before encoding.
+type otlpRecord struct {
+	time         time.Time
+	observed     time.Time
+	severity     int32
+	severityText string
+	body         string
+	attrs        []otlpKeyValue
+	traceID      []byte
+	spanID       []byte
+	flags        uint32
+}
+
+func newOTLPSink(out OutputConfig) (zap.Sink, error) {
+	s, batch, err := parseOTLPOutput(out)
+	if err != nil {
+		return nil, err
+	}
+	s.batcher = newBatcher(batch, s.sendBatch, stderrReporter("otlp", s.poster.url))
+	return s, nil
+}
+
+// parseOTLPOutput reads an otlp output. URL is the collector's base or
+// logs URL, e.g. http://collector:4318; besides the batch and retry options
+// (see batchOptions and newHTTPPoster) it accepts:
+//
+//   - format: protobuf (default) or json
+//   - service_name: default $OTEL_SERVICE_NAME or the program name
+//   - service_version: default the version of the main module, if known
+//   - host_name: default the host name
+//   - resource_attributes: more resource attributes, e.g. "env=prod"
+func parseOTLPOutput(out OutputConfig) (*otlpSink, batchConfig, error) {
+	if out.URL == "" {
+		return nil, batchConfig{}, fmt.Errorf("otlp output requires a url")
+	}
+	u, err := url.Parse(out.URL)
+	if err != nil {
+		return nil, batchConfig{}, fmt.Errorf("invalid otlp url: %w", err)
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = otlpLogsPath
+		out.URL = u.String()
+	}
+
+	serviceName := os.Getenv("OTEL_SERVICE_NAME")
+	if serviceName == "" {
+		serviceName = filepath.Base(os.Args[0])
+	}
+	var serviceVersion string
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "(devel)" {
+		serviceVersion = info.Main.Version
+	}
+	hostName, _ := os.Hostname()
+
+	opts := newOutputOptions(out.Options)
+	s := &otlpSink{
+		poster: newHTTPPoster(out, opts),
+		format: opts.stringOpt("format", OTLPProtobuf, OTLPProtobuf, OTLPJSON),
+	}
+	for _, attr := range []struct{ key, option, def string }{
+		{"service.name", "service_name", serviceName},
+		{"service.version", "service_version", serviceVersion},
+		{"host.name", "host_name", hostName},
+	} {
+		if value := opts.stringOpt(attr.option, attr.def); value != "" {
+			s.resource = append(s.resource, otlpKeyValue{attr.key, value})
+		}
+	}
+	extra := opts.mapOpt("resource_attributes")
+	keys := make([]string, 0, len(extra))
+	for key := range extra {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		s.resource = append(s.resource, otlpKeyValue{key, extra[key]})
+	}
+	batch := batchOptions(opts)
+	return s, batch, opts.err()
+}
+
+// otlpSeverity maps a level to its SeverityNumber and SeverityText. Custom
+// levels below debug are TRACE; V entries are logged at debug.
+func otlpSeverity(level zapcore.Level) (int32, string) {
+	switch {
+	case level < zapcore.DebugLevel:
+		return max(1, 5-int32(zapcore.DebugLevel-level)), "TRACE"
+	case level == zapcore.DebugLevel:
+		return 5, "DEBUG"
+	case level == zapcore.InfoLevel:
+		return 9, "INFO"
+	case level == zapcore.WarnLevel:
+		return 13, "WARN"
+	case level == zapcore.ErrorLevel:
+		return 17, "ERROR"
+	case level == zapcore.DPanicLevel:
+		return 21, "DPANIC"
+	case level == zapcore.PanicLevel:
+		return 22, "PANIC"
+	}
+	return 23, "FATAL"
+}
+
+// WriteEntry converts ent into a log record: the fields become attributes,
+// along with the caller (code.file.path, code.line.number,
+// code.function.name) and the stack (code.stacktrace). The logger name is
+// the instrumentation scope. The trace fields added by the context-aware
+// functions become the trace and span IDs of the record.
+func (s *otlpSink) WriteEntry(ent zapcore.Entry, fields []zapcore.Field, _ []byte) error {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+	rec := otlpRecord{time: ent.Time, observed: time.Now(), body: ent.Message}
+	rec.traceID, rec.spanID, rec.flags = otlpTraceContext(enc.Fields)
+	rec.attrs = otlpAttributes(enc.Fields)
+	rec.severity, rec.severityText = otlpSeverity(ent.Level)
+	if ent.Caller.Defined {
+		rec.attrs = append(rec.attrs,
+			otlpKeyValue{"code.file.path", ent.Caller.File},
+			otlpKeyValue{"code.line.number", int64(ent.Caller.Line)})
+		if ent.Caller.Function != "" {
+			rec.attrs = append(rec.attrs, otlpKeyValue{"code.function.name", ent.Caller.Function})
+		}
+	}
+	if ent.Stack != "" {
+		rec.attrs = append(rec.attrs, otlpKeyValue{"code.stacktrace", ent.Stack})
+	}
+
+	scope := ent.LoggerName
+	if scope == "" {
+		scope = otlpDefaultScope
+	}
+	var data []byte
+	if s.format == OTLPJSON {
+		data = otlpJSONRecord(rec)
+	} else {
+		data = otlpProtoRecord(rec)
+	}
+	return s.add(batchItem{ent: ent, data: data, extra: scope})
+}
+
+func (s *otlpSink) Write(p []byte) (int, error) {
+	ent := zapcore.Entry{Level: zapcore.InfoLevel, Time: time.Now(), Message: string(bytes.TrimRight(p, "\n"))}
+	if err := s.WriteEntry(ent, nil, nil); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Sync sends the pending entries and returns the last failure, if any.
+func (s *otlpSink) Sync() error {
+	return s.flush()
+}
+
+func (s *otlpSink) Close() error {
+	err := s.close()
+	s.poster.close()
+	return err
+}
+
+// otlpTraceContext removes valid trace fields (see TraceFields) from fields
+// and returns them decoded.
+func otlpTraceContext(fields map[string]interface{}) (traceID, spanID []byte, flags uint32) {
+	traceHex, _ := fields[TraceIDKey].(string)
+	spanHex, _ := fields[SpanIDKey].(string)
+	traceID, err := hex.DecodeString(traceHex)
+	if err != nil || len(traceID) != 16 {
+		return nil, nil, 0
+	}
+	spanID, err = hex.DecodeString(spanHex)
+	if err != nil || len(spanID) != 8 {
+		return nil, nil, 0
+	}
+	delete(fields, TraceIDKey)
+	delete(fields, SpanIDKey)
+	if flagsHex, ok := fields[TraceFlagsKey].(string); ok {
+		if b, err := hex.DecodeString(flagsHex); err == nil && len(b) == 1 {
+			flags = uint32(b[0])
+			delete(fields, TraceFlagsKey)
+		}
+	}
+	return traceID, spanID, flags
+}
+
+// otlpAttributes converts the fields collected by a MapObjectEncoder,
+// sorted by key.
+func otlpAttributes(fields map[string]interface{}) []otlpKeyValue {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	attrs := make([]otlpKeyValue, 0, len(keys))
+	for _, key := range keys {
+		attrs = append(attrs, otlpKeyValue{key, otlpValue(fields[key])})
+	}
+	return attrs
+}
+
+// otlpValue converts a value of a MapObjectEncoder to an attribute value.
+func otlpValue(v interface{}) interface{} {
+	switch x := v.(type) {
+	case string, bool, int64, float64, []byte:
+		return x
+	case int:
+		return int64(x)
+	case int32:
+		return int64(x)
+	case int16:
+		return int64(x)
+	case int8:
+		return int64(x)
+	case uint32:
+		return int64(x)
+	case uint16:
+		return int64(x)
+	case uint8:
+		return int64(x)
+	case uint:
+		return otlpUint(uint64(x))
+	case uint64:
+		return otlpUint(x)
+	case uintptr:
+		return otlpUint(uint64(x))
+	case float32:
+		return float64(x)
+	case time.Time:
+		return x.Format(time.RFC3339Nano)
+	case time.Duration:
+		return x.String()
+	case map[string]interface{}:
+		return otlpAttributes(x)
+	case []interface{}:
+		values := make([]interface{}, len(x))
+		for i, item := range x {
+			values[i] = otlpValue(item)
+		}
+		return values
+	case error:
+		return x.Error()
+	case fmt.Stringer:
+		return x.String()
+	}
+	if b, err := json.Marshal(v); err == nil {
+		return string(b)
+	}
+	return fmt.Sprint(v)
+}
+
+// otlpUint converts u to an int value, or to a string if it does not fit.
+func otlpUint(u uint64) interface{} {
+	if u > math.MaxInt64 {
+		return strconv.FormatUint(u, 10)
+	}
+	return int64(u)
+}
+
+func (s *otlpSink) sendBatch(items []batchItem) error {
+	// Group the records by scope, in order of first appearance.
+	var scopes []string
+	records := make(map[string][][]byte)
+	for _, it := range items {
+		scope := it.extra.(string)
+		if _, ok := records[scope]; !ok {
+			scopes = append(scopes, scope)
+		}
+		records[scope] = append(records[scope], it.data)
+	}
+
+	if s.format == OTLPJSON {
+		return s.poster.post("application/json", s.jsonRequest(scopes, records))
+	}
+	return s.poster.post("application/x-protobuf", s.protoRequest(scopes, records))
+}
+
+// protoRequest encodes an ExportLogsServiceRequest with one ResourceLogs:
+//
+//	message ExportLogsServiceRequest { repeated ResourceLogs resource_logs = 1; }
+//	message ResourceLogs { Resource resource = 1; repeated ScopeLogs scope_logs = 2; }
+//	message Resource { repeated KeyValue attributes = 1; }
+//	message ScopeLogs { InstrumentationScope scope = 1; repeated LogRecord log_records = 2; }
+//	message InstrumentationScope { string name = 1; }
+func (s *otlpSink) protoRequest(scopes []string, records map[string][][]byte) []byte {
+	var resource []byte
+	for _, kv := range s.resource {
+		resource = appendProtoMessage(resource, 1, appendOTLPKeyValue(nil, kv))
+	}
+	rl := appendProtoMessage(nil, 1, resource)
+	for _, scope := range scopes {
+		sl := appendProtoMessage(nil, 1, protowire.AppendString(protowire.AppendTag(nil, 1, protowire.BytesType), scope))
+		for _, rec := range records[scope] {
+			sl = appendProtoMessage(sl, 2, rec)
+		}
+		rl = appendProtoMessage(rl, 2, sl)
+	}
+	return appendProtoMessage(nil, 1, rl)
+}
+
+// otlpProtoRecord encodes a LogRecord:
+//
+//	message LogRecord {
+//	  fixed64 time_unix_nano = 1;
+//	  fixed64 observed_time_unix_nano = 11;
+//	  SeverityNumber severity_number = 2;
+//	  string severity_text = 3;
+//	  AnyValue body = 5;
+//	  repeated KeyValue attributes = 6;
+//	  fixed32 flags = 8;
+//	  bytes trace_id = 9;
+//	  bytes span_id = 10;
+//	}
+func otlpProtoRecord(rec otlpRecord) []byte {
+	b := protowire.AppendTag(nil, 1, protowire.Fixed64Type)
+	b = protowire.AppendFixed64(b, uint64(rec.time.UnixNano()))
+	b = protowire.AppendTag(b, 11, protowire.Fixed64Type)
+	b = protowire.AppendFixed64(b, uint64(rec.observed.UnixNano()))
+	b = protowire.AppendTag(b, 2, protowire.VarintType)
+	b = protowire.AppendVarint(b, uint64(rec.severity))
+	b = protowire.AppendTag(b, 3, protowire.BytesType)
+	b = protowire.AppendString(b, rec.severityText)
+	b = appendProtoMessage(b, 5, appendOTLPAnyValue(nil, rec.body))
+	for _, kv := range rec.attrs {
+		b = appendProtoMessage(b, 6, appendOTLPKeyValue(nil, kv))
+	}
+	if rec.traceID != nil {
+		b = protowire.AppendTag(b, 8, protowire.Fixed32Type)
+		b = protowire.AppendFixed32(b, rec.flags)
+		b = protowire.AppendTag(b, 9, protowire.BytesType)
+		b = protowire.AppendBytes(b, rec.traceID)
+		b = protowire.AppendTag(b, 10, protowire.BytesType)
+		b = protowire.AppendBytes(b, rec.spanID)
+	}
+	return b
+}
+
+// appendProtoMessage appends msg as the embedded message field num.
+func appendProtoMessage(b []byte, num protowire.Number, msg []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, msg)
+}
+
+// appendOTLPKeyValue encodes message KeyValue { string key = 1; AnyValue value = 2; }.
+func appendOTLPKeyValue(b []byte, kv otlpKeyValue) []byte {
+	b = protowire.AppendTag(b, 1, protowire.BytesType)
+	b = protowire.AppendString(b, kv.key)
+	return appendProtoMessage(b, 2, appendOTLPAnyValue(nil, kv.value))
+}
+
+// appendOTLPAnyValue encodes the AnyValue oneof: string_value = 1,
+// bool_value = 2, int_value = 3, double_value = 4, array_value = 5,
+// kvlist_value = 6 and bytes_value = 7. Arrays and lists hold their values
+// in field 1.
+func appendOTLPAnyValue(b []byte, v interface{}) []byte {
+	switch x := v.(type) {
+	case string:
+		b = protowire.AppendTag(b, 1, protowire.BytesType)
+		b = protowire.AppendString(b, x)
+	case bool:
+		b = protowire.AppendTag(b, 2, protowire.VarintType)
+		b = protowire.AppendVarint(b, protowire.EncodeBool(x))
+	case int64:
+		b = protowire.AppendTag(b, 3, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(x))
+	case float64:
+		b = protowire.AppendTag(b, 4, protowire.Fixed64Type)
+		b = protowire.AppendFixed64(b, math.Float64bits(x))
+	case []interface{}:
+		var values []byte
+		for _, item := range x {
+			values = appendProtoMessage(values, 1, appendOTLPAnyValue(nil, item))
+		}
+		b = appendProtoMessage(b, 5, values)
+	case []otlpKeyValue:
+		var values []byte
+		for _, kv := range x {
+			values = appendProtoMessage(values, 1, appendOTLPKeyValue(nil, kv))
+		}
+		b = appendProtoMessage(b, 6, values)
+	case []byte:
+		b = protowire.AppendTag(b, 7, protowire.BytesType)
+		b = protowire.AppendBytes(b, x)
+	}
+	return b
+}
+
+// jsonRequest encodes the export request in the OTLP JSON encoding, with
+// the records already encoded by otlpJSONRecord.
+func (s *otlpSink) jsonRequest(scopes []string, records map[string][][]byte) []byte {
+	type scopeLogs struct {
+		Scope      map[string]string `json:"scope"`
+		LogRecords []json.RawMessage `json:"logRecords"`
+	}
+	type resourceLogs struct {
+		Resource  map[string]interface{} `json:"resource"`
+		ScopeLogs []scopeLogs            `json:"scopeLogs"`
+	}
+	rl := resourceLogs{Resource: map[string]interface{}{"attributes": otlpJSONKeyValues(s.resource)}}
+	for _, scope := range scopes {
+		sl := scopeLogs{Scope: map[string]string{"name": scope}}
+		for _, rec := range records[scope] {
+			sl.LogRecords = append(sl.LogRecords, rec)
+		}
+		rl.ScopeLogs = append(rl.ScopeLogs, sl)
+	}
+	body, _ := json.Marshal(map[string][]resourceLogs{"resourceLogs": {rl}})
+	return body
+}
+
+// otlpJSONRecord encodes a LogRecord in the OTLP JSON encoding, where
+// 64-bit integers are strings and IDs hex.
+func otlpJSONRecord(rec otlpRecord) []byte {
+	body, _ := json.Marshal(struct {
+		TimeUnixNano         string                   `json:"timeUnixNano"`
+		ObservedTimeUnixNano string                   `json:"observedTimeUnixNano"`
+		SeverityNumber       int32                    `json:"severityNumber"`
+		SeverityText         string                   `json:"severityText"`
+		Body                 map[string]interface{}   `json:"body"`
+		Attributes           []map[string]interface{} `json:"attributes,omitempty"`
+		Flags                uint32                   `json:"flags,omitempty"`
+		TraceID              string                   `json:"traceId,omitempty"`
+		SpanID               string                   `json:"spanId,omitempty"`
+	}{
+		TimeUnixNano:         strconv.FormatInt(rec.time.UnixNano(), 10),
+		ObservedTimeUnixNano: strconv.FormatInt(rec.observed.UnixNano(), 10),
+		SeverityNumber:       rec.severity,
+		SeverityText:         rec.severityText,
+		Body:                 otlpJSONValue(rec.body),
+		Attributes:           otlpJSONKeyValues(rec.attrs),
+		Flags:                rec.flags,
+		TraceID:              hex.EncodeToString(rec.traceID),
+		SpanID:               hex.EncodeToString(rec.spanID),
+	})
+	return body
+}
+
+func otlpJSONKeyValues(kvs []otlpKeyValue) []map[string]interface{} {
+	list := make([]map[string]interface{}, 0, len(kvs))
+	for _, kv := range kvs {
+		list = append(list, map[string]interface{}{"key": kv.key, "value": otlpJSONValue(kv.value)})
+	}
+	return list
+}
+
+// otlpJSONValue encodes an AnyValue, e.g. {"intValue":"3"}.
+func otlpJSONValue(v interface{}) map[string]interface{} {
+	switch x := v.(type) {
+	case string:
+		return map[string]interface{}{"stringValue": x}
+	case bool:
+		return map[string]interface{}{"boolValue": x}
+	case int64:
+		return map[string]interface{}{"intValue": strconv.FormatInt(x, 10)}
+	case float64:
+		switch {
+		case math.IsNaN(x):
+			return map[string]interface{}{"doubleValue": "NaN"}
+		case math.IsInf(x, 1):
+			return map[string]interface{}{"doubleValue": "Infinity"}
+		case math.IsInf(x, -1):
+			return map[string]interface{}{"doubleValue": "-Infinity"}
+		}
+		return map[string]interface{}{"doubleValue": x}
+	case []interface{}:
+		values := make([]map[string]interface{}, 0, len(x))
+		for _, item := range x {
+			values = append(values, otlpJSONValue(item))
+		}
+		return map[string]interface{}{"arrayValue": map[string]interface{}{"values": values}}
+	case []otlpKeyValue:
+		return map[string]interface{}{"kvlistValue": map[string]interface{}{"values": otlpJSONKeyValues(x)}}
+	case []byte:
+		return map[string]interface{}{"bytesValue": x}
+	}
+	return map[string]interface{}{}
+}
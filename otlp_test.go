@@ -0,0 +1,301 @@
+package glog
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// protoMessage is a decoded protobuf message: field values by number, as
+// uint64 for varint and fixed fields and []byte for the others.
+type protoMessage map[protowire.Number][]interface{}
+
+func decodeProtoMessage(t *testing.T, b []byte) protoMessage {
+	t.Helper()
+	m := protoMessage{}
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			t.Fatalf("Invalid tag: %v", protowire.ParseError(n))
+		}
+		b = b[n:]
+		var v interface{}
+		switch typ {
+		case protowire.VarintType:
+			v, n = protowire.ConsumeVarint(b)
+		case protowire.Fixed64Type:
+			v, n = protowire.ConsumeFixed64(b)
+		case protowire.Fixed32Type:
+			var u uint32
+			u, n = protowire.ConsumeFixed32(b)
+			v = uint64(u)
+		case protowire.BytesType:
+			v, n = protowire.ConsumeBytes(b)
+		default:
+			t.Fatalf("Unexpected wire type %d", typ)
+		}
+		if n < 0 {
+			t.Fatalf("Invalid field %d: %v", num, protowire.ParseError(n))
+		}
+		m[num] = append(m[num], v)
+		b = b[n:]
+	}
+	return m
+}
+
+func (m protoMessage) message(t *testing.T, num protowire.Number, i int) protoMessage {
+	t.Helper()
+	return decodeProtoMessage(t, m[num][i].([]byte))
+}
+
+// protoAttributes decodes the KeyValue list num of m into Go values.
+func protoAttributes(t *testing.T, m protoMessage, num protowire.Number) map[string]interface{} {
+	t.Helper()
+	attrs := map[string]interface{}{}
+	for i := range m[num] {
+		kv := m.message(t, num, i)
+		attrs[string(kv[1][0].([]byte))] = protoAnyValue(t, kv.message(t, 2, 0))
+	}
+	return attrs
+}
+
+func protoAnyValue(t *testing.T, v protoMessage) interface{} {
+	t.Helper()
+	switch {
+	case v[1] != nil:
+		return string(v[1][0].([]byte))
+	case v[2] != nil:
+		return v[2][0].(uint64) == 1
+	case v[3] != nil:
+		return int64(v[3][0].(uint64))
+	case v[4] != nil:
+		return math.Float64frombits(v[4][0].(uint64))
+	case v[5] != nil:
+		array := v.message(t, 5, 0)
+		var values []interface{}
+		for i := range array[1] {
+			values = append(values, protoAnyValue(t, array.message(t, 1, i)))
+		}
+		return values
+	case v[6] != nil:
+		return protoAttributes(t, v.message(t, 6, 0), 1)
+	}
+	return nil
+}
+
+func TestOTLPOutputProtobuf(t *testing.T) {
+	c := newCollector(t)
+	logger, err := Build(WithConfig(&Config{
+		ShowLine: true,
+		Outputs: []OutputConfig{{
+			Type: "otlp",
+			URL:  c.URL,
+			Options: map[string]string{
+				"service_name":        "checkout",
+				"service_version":     "1.2.3",
+				"host_name":           "web-1",
+				"resource_attributes": "deployment.environment=prod",
+				"batch_delay":         "1h",
+			},
+		}},
+	}))
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	logger.Desugar().Named("db").Error("otlp_error",
+		zap.String("user", "alice"),
+		zap.Int("attempt", 3),
+		zap.Bool("ok", false),
+		zap.Float64("ratio", 0.5),
+		zap.Strings("tags", []string{"a", "b"}),
+		zap.Dict("req", zap.String("method", "GET")),
+	)
+	logger.Info("otlp_info")
+	if err := logger.Sync(); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	c.wait(t, 1)
+
+	req := c.requests[0]
+	if req.URL.Path != otlpLogsPath {
+		t.Errorf("Expected path %s, got %s", otlpLogsPath, req.URL.Path)
+	}
+	if got := req.Header.Get("Content-Type"); got != "application/x-protobuf" {
+		t.Errorf("Expected Content-Type application/x-protobuf, got %q", got)
+	}
+
+	export := decodeProtoMessage(t, []byte(c.Bodies()[0]))
+	if len(export[1]) != 1 {
+		t.Fatalf("Expected one ResourceLogs, got %d", len(export[1]))
+	}
+	rl := export.message(t, 1, 0)
+	resource := protoAttributes(t, rl.message(t, 1, 0), 1)
+	for key, want := range map[string]interface{}{
+		"service.name":           "checkout",
+		"service.version":        "1.2.3",
+		"host.name":              "web-1",
+		"deployment.environment": "prod",
+	} {
+		if resource[key] != want {
+			t.Errorf("Expected resource attribute %s=%v, got %v", key, want, resource[key])
+		}
+	}
+
+	if len(rl[2]) != 2 {
+		t.Fatalf("Expected a scope per logger name, got %d", len(rl[2]))
+	}
+	db := rl.message(t, 2, 0)
+	if name := string(db.message(t, 1, 0)[1][0].([]byte)); name != "db" {
+		t.Errorf("Expected scope db, got %q", name)
+	}
+	if name := string(rl.message(t, 2, 1).message(t, 1, 0)[1][0].([]byte)); name != otlpDefaultScope {
+		t.Errorf("Expected scope %s for the unnamed logger, got %q", otlpDefaultScope, name)
+	}
+
+	rec := db.message(t, 2, 0)
+	if rec[1][0].(uint64) == 0 || rec[11][0].(uint64) == 0 {
+		t.Errorf("Expected timestamps, got %v and %v", rec[1], rec[11])
+	}
+	if rec[2][0].(uint64) != 17 || string(rec[3][0].([]byte)) != "ERROR" {
+		t.Errorf("Expected severity 17 ERROR, got %v %q", rec[2][0], rec[3][0])
+	}
+	if body := protoAnyValue(t, rec.message(t, 5, 0)); body != "otlp_error" {
+		t.Errorf("Expected body otlp_error, got %v", body)
+	}
+	attrs := protoAttributes(t, rec, 6)
+	for key, want := range map[string]interface{}{
+		"user":    "alice",
+		"attempt": int64(3),
+		"ok":      false,
+		"ratio":   0.5,
+	} {
+		if attrs[key] != want {
+			t.Errorf("Expected attribute %s=%v, got %v", key, want, attrs[key])
+		}
+	}
+	if tags, _ := attrs["tags"].([]interface{}); len(tags) != 2 || tags[0] != "a" {
+		t.Errorf("Expected an array attribute, got %v", attrs["tags"])
+	}
+	if req, _ := attrs["req"].(map[string]interface{}); req["method"] != "GET" {
+		t.Errorf("Expected a map attribute, got %v", attrs["req"])
+	}
+	if file, _ := attrs["code.file.path"].(string); !strings.HasSuffix(file, "otlp_test.go") || attrs["code.line.number"] == nil {
+		t.Errorf("Expected the caller, got %v:%v", attrs["code.file.path"], attrs["code.line.number"])
+	}
+}
+
+func TestOTLPOutputJSON(t *testing.T) {
+	c := newCollector(t)
+	s, batch, err := parseOTLPOutput(OutputConfig{Type: "otlp", URL: c.URL + "/custom", Options: map[string]string{"format": "json"}})
+	if err != nil {
+		t.Fatalf("parseOTLPOutput failed: %v", err)
+	}
+	s.batcher = newBatcher(batch, s.sendBatch, func(err error) { t.Errorf("Send failed: %v", err) })
+	defer s.Close()
+
+	now := time.Unix(1700000000, 123)
+	s.WriteEntry(zapcore.Entry{Level: zapcore.WarnLevel, Time: now, Message: "otlp_json", Stack: "goroutine 1 [running]"}, //nolint:errcheck
+		[]zapcore.Field{zap.Int64("big", math.MaxInt64), zap.Float64("inf", math.Inf(1))}, nil)
+	if err := s.Sync(); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+
+	req := c.requests[0]
+	if req.URL.Path != "/custom" || req.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("Unexpected request %s %s", req.URL.Path, req.Header.Get("Content-Type"))
+	}
+	var export struct {
+		ResourceLogs []struct {
+			Resource struct {
+				Attributes []struct {
+					Key   string                 `json:"key"`
+					Value map[string]interface{} `json:"value"`
+				} `json:"attributes"`
+			} `json:"resource"`
+			ScopeLogs []struct {
+				Scope      map[string]string `json:"scope"`
+				LogRecords []struct {
+					TimeUnixNano   string                 `json:"timeUnixNano"`
+					SeverityNumber int                    `json:"severityNumber"`
+					SeverityText   string                 `json:"severityText"`
+					Body           map[string]interface{} `json:"body"`
+					Attributes     []struct {
+						Key   string                 `json:"key"`
+						Value map[string]interface{} `json:"value"`
+					} `json:"attributes"`
+				} `json:"logRecords"`
+			} `json:"scopeLogs"`
+		} `json:"resourceLogs"`
+	}
+	if err := json.Unmarshal([]byte(c.Bodies()[0]), &export); err != nil {
+		t.Fatalf("Body is not JSON: %v", err)
+	}
+
+	rl := export.ResourceLogs[0]
+	resource := map[string]interface{}{}
+	for _, kv := range rl.Resource.Attributes {
+		resource[kv.Key] = kv.Value["stringValue"]
+	}
+	if resource["service.name"] == nil || resource["host.name"] == nil {
+		t.Errorf("Expected default service and host names, got %v", resource)
+	}
+
+	rec := rl.ScopeLogs[0].LogRecords[0]
+	if rec.TimeUnixNano != "1700000000000000123" || rec.SeverityNumber != 13 || rec.SeverityText != "WARN" || rec.Body["stringValue"] != "otlp_json" {
+		t.Errorf("Unexpected record %+v", rec)
+	}
+	attrs := map[string]map[string]interface{}{}
+	for _, kv := range rec.Attributes {
+		attrs[kv.Key] = kv.Value
+	}
+	if attrs["big"]["intValue"] != "9223372036854775807" {
+		t.Errorf("Expected int values as strings, got %v", attrs["big"])
+	}
+	if attrs["inf"]["doubleValue"] != "Infinity" {
+		t.Errorf("Expected Infinity, got %v", attrs["inf"])
+	}
+	if attrs["code.stacktrace"]["stringValue"] != "goroutine 1 [running]" {
+		t.Errorf("Expected the stack, got %v", attrs["code.stacktrace"])
+	}
+}
+
+func TestOTLPSeverity(t *testing.T) {
+	for level, want := range map[zapcore.Level]int32{
+		zapcore.DebugLevel - 2: 3,
+		zapcore.DebugLevel - 9: 1,
+		zapcore.DebugLevel:     5,
+		zapcore.InfoLevel:      9,
+		zapcore.WarnLevel:      13,
+		zapcore.ErrorLevel:     17,
+		zapcore.DPanicLevel:    21,
+		zapcore.PanicLevel:     22,
+		zapcore.FatalLevel:     23,
+	} {
+		if got, _ := otlpSeverity(level); got != want {
+			t.Errorf("Level %v: expected severity %d, got %d", level, want, got)
+		}
+	}
+}
+
+func TestOTLPOutputOptionErrors(t *testing.T) {
+	out := OutputConfig{Type: "otlp", URL: "http://127.0.0.1:1", Options: map[string]string{
+		"format":  "grpc",
+		"service": "api",
+	}}
+	problems := out.validate("outputs[0]")
+	if len(problems) != 1 {
+		t.Fatalf("Expected one options problem, got %q", problems)
+	}
+	for _, want := range []string{"service: unknown option", "format: unknown value"} {
+		if !strings.Contains(problems[0], want) {
+			t.Errorf("Problem should mention %q, got: %s", want, problems[0])
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package glog
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// profilesKey is the config file section holding named profiles. Each profile
+// uses the same keys as the base config and only overrides the keys it sets:
+//
+//	encoder: json
+//	log_level: info
+//	profiles:
+//	  dev:
+//	    encoder: console
+//	    log_level: debug
+//	    log_stdout: true
+//	  prod:
+//	    segment:
+//	      compress: true
+const profilesKey = "profiles"
+
+// WithProfile selects the profile of the config file to apply on top of its
+// base config. It takes precedence over the <prefix>_PROFILE environment
+// variable (GLOG_PROFILE by default). Selecting a profile the file does not
+// define is an error.
+func WithProfile(name string) Option {
+	return func(o *buildOptions) {
+		o.profile = &name
+	}
+}
+
+// selectedProfile returns the profile chosen by WithProfile or, failing that,
+// by the <prefix>_PROFILE environment variable.
+func (o *buildOptions) selectedProfile(prefix string) string {
+	if o.profile != nil {
+		return *o.profile
+	}
+	if prefix == "" {
+		return ""
+	}
+	return os.Getenv(prefix + "_PROFILE")
+}
+
+// splitProfiles removes the profiles section from raw and returns it.
+func splitProfiles(raw map[string]interface{}) (map[string]map[string]interface{}, error) {
+	section, ok := raw[profilesKey]
+	if !ok {
+		return nil, nil
+	}
+	delete(raw, profilesKey)
+
+	entries, ok := section.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s must be a mapping of profile names to configs", profilesKey)
+	}
+	profiles := make(map[string]map[string]interface{}, len(entries))
+	for name, entry := range entries {
+		if entry == nil {
+			profiles[name] = map[string]interface{}{}
+			continue
+		}
+		p, ok := entry.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s.%s must be a mapping", profilesKey, name)
+		}
+		profiles[name] = p
+	}
+	return profiles, nil
+}
+
+// profileUnknownKeys reports keys of every profile that do not belong to Config.
+func profileUnknownKeys(profiles map[string]map[string]interface{}) []string {
+	var problems []string
+	for _, name := range profileNames(profiles) {
+		prefix := profilesKey + "." + name + "."
+		problems = append(problems, unknownKeys(profiles[name], reflect.TypeOf(Config{}), prefix)...)
+	}
+	return problems
+}
+
+// applyProfile overrides the fields of cfg set by the named profile.
+func applyProfile(cfg *Config, profiles map[string]map[string]interface{}, name string) error {
+	p, ok := profiles[name]
+	if !ok {
+		if len(profiles) == 0 {
+			return fmt.Errorf("unknown profile %q: the config file has no %s section", name, profilesKey)
+		}
+		return fmt.Errorf("unknown profile %q (available: %s)", name, strings.Join(profileNames(profiles), ", "))
+	}
+
+	// Round-trip through YAML so only the keys present in the profile are
+	// decoded, whatever the format of the file was.
+	content, err := yaml.Marshal(p)
+	if err != nil {
+		return fmt.Errorf("invalid profile %q: %w", name, err)
+	}
+	if err := yaml.Unmarshal(content, cfg); err != nil {
+		return fmt.Errorf("invalid profile %q: %w", name, err)
+	}
+	return nil
+}
+
+func profileNames(profiles map[string]map[string]interface{}) []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
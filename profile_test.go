@@ -0,0 +1,169 @@
+package glog
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+const profilesConfig = `
+encoder: console
+encode_level: Capital
+log_level: info
+separate_levels: false
+segment:
+  max_size: 10
+  max_backups: 3
+profiles:
+  dev:
+    log_level: debug
+    log_stdout: true
+  prod:
+    encoder: json
+    segment:
+      compress: true
+  empty:
+`
+
+func TestProfileOverridesBase(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "glog_test_profile")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	configPath := writeConfig(t, tempDir, profilesConfig)
+
+	tests := []struct {
+		profile string
+		want    func(cfg *Config) bool
+	}{
+		{"", func(cfg *Config) bool {
+			return cfg.LogLevel == "info" && cfg.Encoder == "console" && !cfg.LogStdout
+		}},
+		{"dev", func(cfg *Config) bool {
+			return cfg.LogLevel == "debug" && cfg.LogStdout && cfg.Encoder == "console"
+		}},
+		{"prod", func(cfg *Config) bool {
+			// Nested keys are merged, not replaced.
+			return cfg.Encoder == "json" && cfg.Segment.Compress && cfg.Segment.MaxSize == 10 && cfg.Segment.MaxBackups == 3
+		}},
+		{"empty", func(cfg *Config) bool {
+			return cfg.LogLevel == "info" && cfg.Encoder == "console"
+		}},
+	}
+	for _, tt := range tests {
+		cfg := &Config{}
+		if _, err := readConfigFile(configPath, "", cfg, tt.profile, false); err != nil {
+			t.Fatalf("readConfigFile(profile %q) failed: %v", tt.profile, err)
+		}
+		if !tt.want(cfg) {
+			t.Errorf("Profile %q: unexpected config %+v", tt.profile, cfg)
+		}
+	}
+}
+
+func TestProfileSelection(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "glog_test_profile_select")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	configPath := writeConfig(t, tempDir, profilesConfig)
+
+	t.Setenv("GLOG_PROFILE", "dev")
+	logger, err := Build(WithConfigFile(configPath), WithDirectory(tempDir))
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if !logger.Desugar().Core().Enabled(zap.DebugLevel) {
+		t.Error("GLOG_PROFILE=dev should enable debug logging")
+	}
+
+	// WithProfile wins over the environment variable.
+	logger, err = Build(WithConfigFile(configPath), WithDirectory(tempDir), WithProfile("prod"))
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if logger.Desugar().Core().Enabled(zap.DebugLevel) {
+		t.Error("WithProfile(prod) should override GLOG_PROFILE=dev")
+	}
+
+	// Without an environment prefix GLOG_PROFILE is ignored.
+	logger, err = Build(WithConfigFile(configPath), WithDirectory(tempDir), WithEnvPrefix(""))
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if logger.Desugar().Core().Enabled(zap.DebugLevel) {
+		t.Error("GLOG_PROFILE should be ignored when environment overrides are disabled")
+	}
+}
+
+func TestProfileJSON(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "glog_test_profile_json")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	configPath := writeFile(t, tempDir, "logger.json",
+		`{"log_level": "info", "segment": {"max_size": 5}, "profiles": {"prod": {"log_level": "warn", "segment": {"max_age": 30}}}}`)
+
+	cfg := &Config{}
+	if _, err := readConfigFile(configPath, "", cfg, "prod", false); err != nil {
+		t.Fatalf("readConfigFile failed: %v", err)
+	}
+	if cfg.LogLevel != "warn" || cfg.Segment.MaxSize != 5 || cfg.Segment.MaxAge != 30 {
+		t.Errorf("Unexpected config %+v", cfg)
+	}
+}
+
+func TestUnknownProfile(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "glog_test_profile_unknown")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	configPath := writeConfig(t, tempDir, profilesConfig)
+	_, err = Build(WithConfigFile(configPath), WithDirectory(tempDir), WithProfile("staging"))
+	if err == nil || !strings.Contains(err.Error(), "available: dev, empty, prod") {
+		t.Errorf("Expected an unknown profile error listing the profiles, got: %v", err)
+	}
+
+	configPath = writeConfig(t, tempDir, baseConsoleConfig)
+	if _, err := Build(WithConfigFile(configPath), WithDirectory(tempDir), WithProfile("dev")); err == nil {
+		t.Error("Expected an error for a profile in a file without profiles, got nil")
+	}
+
+	if _, err := Build(WithConfig(&Config{Directory: tempDir}), WithProfile("dev")); err == nil {
+		t.Error("Expected an error for a profile without a config file, got nil")
+	}
+}
+
+func TestStrictModeProfileKeys(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "glog_test_profile_strict")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	configPath := writeConfig(t, tempDir, profilesConfig+`
+  typo:
+    log_levle: debug
+`)
+	_, err = Build(WithConfigFile(configPath), WithDirectory(tempDir), WithStrict())
+
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Expected *ValidationError, got %T: %v", err, err)
+	}
+	want := `unknown key "profiles.typo.log_levle" (did you mean "profiles.typo.log_level"?)`
+	if len(verr.Problems) != 1 || verr.Problems[0] != want {
+		t.Errorf("Expected only %q, got %v", want, verr.Problems)
+	}
+}
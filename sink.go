@@ -0,0 +1,478 @@
+package glog
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// OutputConfig configures one entry of Config.Outputs.
+type OutputConfig struct {
+	// Type is the sink: file, stdout, stderr, tcp, udp, unix, unixgram,
+	// syslog, http, loki, otlp or a name registered with RegisterSink.
+	Type string `yaml:"type" json:"type" toml:"type"`
+	// Level is the minimum level of the output, on top of the logger level.
+	Level string `yaml:"level,omitempty" json:"level,omitempty" toml:"level,omitempty"`
+	// Encoder and EncodeLevel override those of Config for this output.
+	Encoder     string `yaml:"encoder,omitempty" json:"encoder,omitempty" toml:"encoder,omitempty"`
+	EncodeLevel string `yaml:"encode_level,omitempty" json:"encode_level,omitempty" toml:"encode_level,omitempty"`
+	// Path is the file of a file output, relative to the log directory;
+	// defaults to app.log.
+	Path string `yaml:"path,omitempty" json:"path,omitempty" toml:"path,omitempty"`
+	// Segment overrides the rotation settings of Config for a file output.
+	Segment *Segment `yaml:"segment,omitempty" json:"segment,omitempty" toml:"segment,omitempty"`
+	// Address is the host:port or socket path of network and syslog outputs.
+	Address string `yaml:"address,omitempty" json:"address,omitempty" toml:"address,omitempty"`
+	// URL is the endpoint of an http, loki or otlp output.
+	URL string `yaml:"url,omitempty" json:"url,omitempty" toml:"url,omitempty"`
+	// Headers are added to the requests of an http, loki or otlp output.
+	Headers map[string]string `yaml:"headers,omitempty" json:"headers,omitempty" toml:"headers,omitempty"`
+	// Options holds type-specific settings, e.g. the facility of a syslog
+	// output, and those of sinks registered with RegisterSink.
+	Options map[string]string `yaml:"options,omitempty" json:"options,omitempty" toml:"options,omitempty"`
+}
+
+// errSinkClosed is returned by writes to a sink that was closed.
+var errSinkClosed = errors.New("sink is closed")
+
+// SinkFactory creates the sink of an output. It is called every time a
+// logger is built; the sink is closed by (*Logger).Close, or when a global
+// logger is replaced by Init or a reload. Encoder and EncodeLevel are set
+// to their effective values, and file outputs get Path resolved against the
+// log directory and Segment filled from Config, before the factory is
+// called.
+type SinkFactory func(out OutputConfig) (zap.Sink, error)
+
+// EntrySink may be implemented by a sink that needs the entry itself, e.g.
+// its level or logger name, and not only the encoded bytes. WriteEntry is
+// then called instead of Write with the entry, its fields, including those
+// added with With, and the entry encoded by the output's encoder.
+type EntrySink interface {
+	WriteEntry(ent zapcore.Entry, fields []zapcore.Field, p []byte) error
+}
+
+var sinks = struct {
+	sync.RWMutex
+	factories map[string]SinkFactory
+}{factories: map[string]SinkFactory{
+	"file":     newFileSink,
+	"stdout":   newStdoutSink,
+	"stderr":   newStderrSink,
+	"tcp":      newNetSink,
+	"udp":      newNetSink,
+	"unix":     newNetSink,
+	"unixgram": newNetSink,
+	"syslog":   newSyslogSink,
+	"http":     newHTTPSink,
+	"loki":     newLokiSink,
+	"otlp":     newOTLPSink,
+}}
+
+// RegisterSink makes a sink available as an output type. It returns an
+// error if name is empty or already registered.
+//
+// 示例：
+//
+//	glog.RegisterSink("kafka", func(out glog.OutputConfig) (zap.Sink, error) {
+//		return newKafkaSink(out.Address, out.Options["topic"])
+//	})
+//
+//	// logger.yaml
+//	// outputs:
+//	//   - type: kafka
+//	//     address: kafka:9092
+//	//     options: {topic: logs}
+func RegisterSink(name string, factory SinkFactory) error {
+	if name == "" {
+		return fmt.Errorf("sink name must not be empty")
+	}
+	if factory == nil {
+		return fmt.Errorf("sink %q: factory must not be nil", name)
+	}
+	sinks.Lock()
+	defer sinks.Unlock()
+	if _, ok := sinks.factories[name]; ok {
+		return fmt.Errorf("sink %q is already registered", name)
+	}
+	sinks.factories[name] = factory
+	return nil
+}
+
+func sinkFactory(name string) (SinkFactory, bool) {
+	sinks.RLock()
+	defer sinks.RUnlock()
+	factory, ok := sinks.factories[name]
+	return factory, ok
+}
+
+// sinkNames returns the registered output types.
+func sinkNames() []string {
+	sinks.RLock()
+	defer sinks.RUnlock()
+	names := make([]string, 0, len(sinks.factories))
+	for name := range sinks.factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// newOutputsLogger builds a logger writing to cfg.Outputs instead of the
+// log files selected by separate_levels.
+func newOutputsLogger(cfg *Config, logLevel zapcore.LevelEnabler, queue *asyncQueue) (*zap.SugaredLogger, writerSet, error) {
+	path := cfg.Path + cfg.Directory
+	var writers writerSet
+	cores := make([]zapcore.Core, 0, len(cfg.Outputs))
+	for i, out := range cfg.Outputs {
+		core, err := newOutputCore(cfg, out, logLevel, &writers, queue)
+		if err != nil {
+			writers.Close() //nolint:errcheck
+			return nil, nil, fmt.Errorf("outputs[%d] (%s): %w", i, out.Type, err)
+		}
+		cores = append(cores, core)
+	}
+
+	logger := zap.New(zapcore.NewTee(cores...))
+	if cfg.ShowLine && !cfg.HighPerformance {
+		logger = logger.WithOptions(zap.AddCaller())
+	}
+
+	if path != "" && !cfg.StderrOnly {
+		if err := mkdir(path); err == nil {
+			panicRedirect(path + FileStderr)
+		}
+	}
+	return logger.Sugar(), writers, nil
+}
+
+// newOutputCore creates the sink of out and the core writing to it.
+func newOutputCore(cfg *Config, out OutputConfig, logLevel zapcore.LevelEnabler, writers *writerSet, queue *asyncQueue) (zapcore.Core, error) {
+	factory, ok := sinkFactory(out.Type)
+	if !ok {
+		return nil, fmt.Errorf("unknown output type %q (registered: %s)", out.Type, strings.Join(sinkNames(), ", "))
+	}
+
+	var enabler zapcore.LevelEnabler = logLevel
+	if out.Level != "" {
+		min, err := parseLevel(out.Level)
+		if err != nil {
+			return nil, err
+		}
+		enabler = zap.LevelEnablerFunc(func(level zapcore.Level) bool {
+			return level >= min && logLevel.Enabled(level)
+		})
+	}
+
+	// Factories see the effective encoder of the output. Only stdout and
+	// stderr use the console settings and keep colors, if they are terminals.
+	var outCfg *Config
+	switch out.Type {
+	case "stdout":
+		outCfg = destinationConfig(cfg, true, isTerminal(os.Stdout), out.Encoder, out.EncodeLevel)
+	case "stderr":
+		outCfg = destinationConfig(cfg, true, isTerminal(stderrOrig), out.Encoder, out.EncodeLevel)
+	default:
+		outCfg = destinationConfig(cfg, false, false, out.Encoder, out.EncodeLevel)
+	}
+	out.Encoder, out.EncodeLevel = outCfg.Encoder, outCfg.EncodeLevel
+	if out.Type == "file" {
+		out = resolveFileOutput(cfg, out)
+	}
+
+	sink, err := factory(out)
+	if err != nil {
+		return nil, err
+	}
+	*writers = append(*writers, sink)
+	return newSinkCore(enabler, getEncoder(outCfg), sink, queue), nil
+}
+
+// resolveFileOutput resolves the path of a file output against the log
+// directory and fills in the rotation settings of cfg.
+func resolveFileOutput(cfg *Config, out OutputConfig) OutputConfig {
+	if out.Path == "" {
+		out.Path = "app.log"
+	}
+	if !filepath.IsAbs(out.Path) {
+		out.Path = filepath.Join(cfg.Path+cfg.Directory, out.Path)
+	}
+	if out.Segment == nil {
+		segment := cfg.Segment
+		out.Segment = &segment
+	}
+	return out
+}
+
+// sinkCore writes entries encoded by enc to sink. Unlike zapcore.NewCore it
+// hands entries to sinks implementing EntrySink, and with a queue it leaves
+// writing the encoded entries to the async goroutine, see AsyncConfig.
+type sinkCore struct {
+	zapcore.LevelEnabler
+	enc       zapcore.Encoder
+	sink      zapcore.WriteSyncer
+	entrySink EntrySink
+	// fields are the fields added with With, kept for entry sinks only.
+	fields []zapcore.Field
+	queue  *asyncQueue
+}
+
+func newSinkCore(enab zapcore.LevelEnabler, enc zapcore.Encoder, sink zapcore.WriteSyncer, queue *asyncQueue) *sinkCore {
+	c := &sinkCore{LevelEnabler: enab, enc: enc, sink: sink, queue: queue}
+	c.entrySink, _ = sink.(EntrySink)
+	return c
+}
+
+// newCore is zapcore.NewCore, with entries queued in queue if not nil.
+func newCore(enc zapcore.Encoder, ws zapcore.WriteSyncer, enab zapcore.LevelEnabler, queue *asyncQueue) zapcore.Core {
+	if queue == nil {
+		return zapcore.NewCore(enc, ws, enab)
+	}
+	return newSinkCore(enab, enc, ws, queue)
+}
+
+func (c *sinkCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	clone.enc = c.enc.Clone()
+	for _, f := range fields {
+		f.AddTo(clone.enc)
+	}
+	if c.entrySink != nil {
+		clone.fields = append(append([]zapcore.Field(nil), c.fields...), fields...)
+	}
+	return &clone
+}
+
+func (c *sinkCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *sinkCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+	if c.queue != nil && c.entrySink == nil {
+		// Panic and fatal entries end the program (or goroutine) right after
+		// Write returns, so write them and everything queued before them now.
+		if ent.Level <= zapcore.ErrorLevel && c.queue.push(asyncItem{level: ent.Level, out: c.sink, buf: buf}) {
+			return nil
+		}
+		c.queue.flush()
+	}
+	if c.entrySink != nil {
+		all := fields
+		if len(c.fields) > 0 {
+			all = append(append(make([]zapcore.Field, 0, len(c.fields)+len(fields)), c.fields...), fields...)
+		}
+		err = c.entrySink.WriteEntry(ent, all, buf.Bytes())
+	} else {
+		_, err = c.sink.Write(buf.Bytes())
+	}
+	buf.Free()
+	if err != nil {
+		return err
+	}
+	if ent.Level > zapcore.ErrorLevel {
+		// Like zapcore.NewCore, sync before a panic or fatal exit.
+		return c.Sync()
+	}
+	return nil
+}
+
+func (c *sinkCore) Sync() error {
+	if c.queue != nil {
+		c.queue.flush()
+	}
+	return c.sink.Sync()
+}
+
+// fileSink is a rotating log file.
+type fileSink struct {
+	*lumberjack.Logger
+}
+
+func (fileSink) Sync() error { return nil }
+
+func newFileSink(out OutputConfig) (zap.Sink, error) {
+	if out.Path == "" {
+		return nil, fmt.Errorf("file output requires a path")
+	}
+	if err := mkdir(filepath.Dir(out.Path)); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+	var segment Segment
+	if out.Segment != nil {
+		segment = *out.Segment
+	}
+	return fileSink{&lumberjack.Logger{
+		Filename:   out.Path,
+		MaxSize:    segment.MaxSize,
+		MaxBackups: segment.MaxBackups,
+		MaxAge:     segment.MaxAge,
+		Compress:   segment.Compress,
+		LocalTime:  true,
+	}}, nil
+}
+
+// nopCloserSink is a sink that must not be closed, such as stdout.
+type nopCloserSink struct {
+	zapcore.WriteSyncer
+}
+
+func (nopCloserSink) Close() error { return nil }
+
+func newStdoutSink(OutputConfig) (zap.Sink, error) {
+	return nopCloserSink{zapcore.Lock(os.Stdout)}, nil
+}
+
+func newStderrSink(OutputConfig) (zap.Sink, error) {
+	return nopCloserSink{stderr}, nil
+}
+
+// outputOptions reads the Options of an output and collects every problem,
+// including options nobody asked for, so they can be reported together.
+type outputOptions struct {
+	opts     map[string]string
+	known    []string
+	problems []string
+}
+
+func newOutputOptions(opts map[string]string) *outputOptions {
+	return &outputOptions{opts: opts}
+}
+
+// lookup returns the value of key and whether it is set.
+func (o *outputOptions) lookup(key string) (string, bool) {
+	o.known = append(o.known, key)
+	v, ok := o.opts[key]
+	return v, ok && v != ""
+}
+
+// invalid records a problem with the value of key.
+func (o *outputOptions) invalid(key string, format string, args ...interface{}) {
+	o.problems = append(o.problems, key+": "+fmt.Sprintf(format, args...))
+}
+
+// stringOpt returns the value of key, which must be one of allowed if any.
+func (o *outputOptions) stringOpt(key, def string, allowed ...string) string {
+	v, ok := o.lookup(key)
+	if !ok {
+		return def
+	}
+	if len(allowed) > 0 && !slices.Contains(allowed, v) {
+		o.invalid(key, "unknown value %q (want %s)", v, strings.Join(allowed, ", "))
+		return def
+	}
+	return v
+}
+
+// intOpt returns the value of key, which must be a positive integer.
+func (o *outputOptions) intOpt(key string, def int) int {
+	v, ok := o.lookup(key)
+	if !ok {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		o.invalid(key, "want a positive integer, got %q", v)
+		return def
+	}
+	return n
+}
+
+// durationOpt returns the value of key, which must be a positive duration.
+func (o *outputOptions) durationOpt(key string, def time.Duration) time.Duration {
+	v, ok := o.lookup(key)
+	if !ok {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		o.invalid(key, "want a positive duration such as 5s, got %q", v)
+		return def
+	}
+	return d
+}
+
+// boolOpt returns the value of key, which must be a boolean.
+func (o *outputOptions) boolOpt(key string, def bool) bool {
+	v, ok := o.lookup(key)
+	if !ok {
+		return def
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		o.invalid(key, "want true or false, got %q", v)
+		return def
+	}
+	return b
+}
+
+// mapOpt returns the value of key given as comma-separated key=value
+// pairs such as "app=api,env=prod".
+func (o *outputOptions) mapOpt(key string) map[string]string {
+	v, ok := o.lookup(key)
+	if !ok {
+		return nil
+	}
+	var m map[string]string
+	if err := setFromString(reflect.ValueOf(&m).Elem(), v); err != nil {
+		o.invalid(key, "%v", err)
+		return nil
+	}
+	return m
+}
+
+// listOpt returns the value of key given as a comma-separated list.
+func (o *outputOptions) listOpt(key string) []string {
+	v, ok := o.lookup(key)
+	if !ok {
+		return nil
+	}
+	var list []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// err reports unknown options and invalid values. Call it after reading
+// every option.
+func (o *outputOptions) err() error {
+	var unknown []string
+	for key := range o.opts {
+		if !slices.Contains(o.known, key) {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	known := append([]string(nil), o.known...)
+	sort.Strings(known)
+
+	var problems []string
+	for _, key := range unknown {
+		problems = append(problems, fmt.Sprintf("%s: unknown option (want %s)", key, strings.Join(known, ", ")))
+	}
+	problems = append(problems, o.problems...)
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,291 @@
+package glog
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// memorySink records what it is given, as bytes and as entries.
+type memorySink struct {
+	mu      sync.Mutex
+	buf     strings.Builder
+	entries []zapcore.Entry
+	fields  [][]zapcore.Field
+	closed  bool
+}
+
+func (s *memorySink) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.buf.Write(p)
+}
+
+func (s *memorySink) WriteEntry(ent zapcore.Entry, fields []zapcore.Field, p []byte) error {
+	s.mu.Lock()
+	s.entries = append(s.entries, ent)
+	s.fields = append(s.fields, fields)
+	s.mu.Unlock()
+	_, err := s.Write(p)
+	return err
+}
+
+func (s *memorySink) Sync() error { return nil }
+
+func (s *memorySink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return nil
+}
+
+func (s *memorySink) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.buf.String()
+}
+
+// registerMemorySink registers a sink type returning sink, unique per test
+// and removed when the test ends.
+func registerMemorySink(t *testing.T, sink *memorySink) string {
+	t.Helper()
+	name := "memory-" + t.Name()
+	if err := RegisterSink(name, func(OutputConfig) (zap.Sink, error) { return sink, nil }); err != nil {
+		t.Fatalf("RegisterSink failed: %v", err)
+	}
+	t.Cleanup(func() {
+		sinks.Lock()
+		delete(sinks.factories, name)
+		sinks.Unlock()
+	})
+	return name
+}
+
+func TestOutputsLevelsAndEncoders(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "glog_test_outputs")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	mem := &memorySink{}
+	name := registerMemorySink(t, mem)
+
+	logger, err := Build(WithConfig(&Config{
+		Directory: tempDir,
+		LogLevel:  "debug",
+		Encoder:   "console",
+		Outputs: []OutputConfig{
+			{Type: "file", Path: "errors/errors.log", Level: "warn", Encoder: "json"},
+			{Type: "file"},
+			{Type: name, EncodeLevel: CapitalLevelEncoder},
+		},
+	}))
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	logger.With("request_id", "r1").Named("db").Debugw("outputs_debug", "rows", 3)
+	logger.Warn("outputs_warn")
+
+	content, err := os.ReadFile(filepath.Join(tempDir, "errors", "errors.log"))
+	if err != nil {
+		t.Fatalf("Failed to read file output: %v", err)
+	}
+	if strings.Contains(string(content), "outputs_debug") || !strings.Contains(string(content), `"message":"outputs_warn"`) {
+		t.Errorf("The warn output should hold only the warn entry as JSON. Content: %s", content)
+	}
+	checkLogFile(t, filepath.Join(tempDir, "app.log"), "debug", "outputs_debug")
+	if _, err := os.Stat(filepath.Join(tempDir, FileDebug)); !os.IsNotExist(err) {
+		t.Error("Outputs should replace the separate level files")
+	}
+
+	if !strings.Contains(mem.String(), "DEBUG") {
+		t.Errorf("The encode_level of the output should be used. Got: %s", mem.String())
+	}
+	if len(mem.entries) != 2 || mem.entries[0].LoggerName != "db" || mem.entries[1].Level != zap.WarnLevel {
+		t.Fatalf("Entry sinks should receive the entries, got %+v", mem.entries)
+	}
+	// Fields added with With are passed along with the call-site fields.
+	if len(mem.fields[0]) != 2 || mem.fields[0][0].Key != "request_id" || mem.fields[0][1].Key != "rows" {
+		t.Errorf("Unexpected fields %+v", mem.fields[0])
+	}
+}
+
+func TestOutputsClosedOnInit(t *testing.T) {
+	mem := &memorySink{}
+	name := registerMemorySink(t, mem)
+
+	if err := InitWithConfig(&Config{Outputs: []OutputConfig{{Type: name}}}); err != nil {
+		t.Fatalf("InitWithConfig failed: %v", err)
+	}
+	Info("outputs_global_info")
+	if !strings.Contains(mem.String(), "outputs_global_info") {
+		t.Errorf("Global logger should write to the output. Got: %s", mem.String())
+	}
+
+	tempDir, err := os.MkdirTemp("", "glog_test_outputs_reload")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+	if err := InitWithConfig(&Config{Directory: tempDir}); err != nil {
+		t.Fatalf("InitWithConfig failed: %v", err)
+	}
+	if !mem.closed {
+		t.Error("The sink of the previous logger should be closed by a second Init")
+	}
+}
+
+func TestLoggerClose(t *testing.T) {
+	mem := &memorySink{}
+	name := registerMemorySink(t, mem)
+
+	logger, err := Build(WithConfig(&Config{StderrOnly: true, Outputs: []OutputConfig{{Type: name}}}))
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	logger.Info("logger_close_info")
+	if err := logger.Close(); err != nil {
+		t.Errorf("Close failed: %v", err)
+	}
+	if !mem.closed {
+		t.Error("Close should close the outputs of the logger")
+	}
+	if err := logger.Close(); err != nil {
+		t.Errorf("A second Close should be a no-op, got %v", err)
+	}
+}
+
+func TestNetworkOutputs(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	defer ln.Close()
+	lines := make(chan string, 10)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		scanner := bufio.NewScanner(conn)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("ListenPacket failed: %v", err)
+	}
+	defer pc.Close()
+
+	var mu sync.Mutex
+	var posted []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		posted = append(posted, r.Header.Get("Content-Type")+" "+string(body))
+		mu.Unlock()
+	}))
+	defer ts.Close()
+
+	logger, err := Build(WithConfig(&Config{
+		Encoder: "json",
+		Outputs: []OutputConfig{
+			{Type: "tcp", Address: ln.Addr().String()},
+			{Type: "syslog", Address: "udp://" + pc.LocalAddr().String(), Encoder: "console"},
+			{Type: "http", URL: ts.URL},
+		},
+	}))
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	logger.Error("network_error_message")
+
+	select {
+	case line := <-lines:
+		if !strings.Contains(line, `"message":"network_error_message"`) {
+			t.Errorf("Unexpected tcp line %q", line)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the tcp output")
+	}
+
+	buf := make([]byte, 4096)
+	pc.SetReadDeadline(time.Now().Add(5 * time.Second)) //nolint:errcheck
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("Failed to read syslog message: %v", err)
+	}
+	// facility user (1) * 8 + severity error (3)
+	if msg := string(buf[:n]); !strings.HasPrefix(msg, "<11>") || !strings.Contains(msg, "network_error_message") {
+		t.Errorf("Unexpected syslog message %q", msg)
+	}
+
+	// http outputs send entries in batches.
+	if err := logger.Sync(); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(posted) != 1 || !strings.HasPrefix(posted[0], "application/x-ndjson ") || !strings.Contains(posted[0], "network_error_message") {
+		t.Errorf("Unexpected http posts %q", posted)
+	}
+}
+
+func TestOutputErrors(t *testing.T) {
+	if _, err := Build(WithConfig(&Config{Outputs: []OutputConfig{{Type: "carrier-pigeon"}}})); err == nil ||
+		!strings.Contains(err.Error(), "unknown output type") {
+		t.Errorf("Expected an unknown output type error, got: %v", err)
+	}
+	if _, err := Build(WithConfig(&Config{Outputs: []OutputConfig{{Type: "stdout", Level: "loud"}}})); err == nil {
+		t.Error("Expected an error for an invalid output level, got nil")
+	}
+
+	if err := RegisterSink("file", newFileSink); err == nil {
+		t.Error("Expected an error when registering a sink twice, got nil")
+	}
+	if err := RegisterSink("", newFileSink); err == nil {
+		t.Error("Expected an error for an empty sink name, got nil")
+	}
+}
+
+func TestOutputsValidateAndStrict(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "glog_test_outputs_strict")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	configPath := writeConfig(t, tempDir, `
+outputs:
+  - type: tcp
+    adress: localhost:5000
+  - type: stdout
+    encoder: jsno
+`)
+	_, err = Build(WithConfigFile(configPath), WithStrict())
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Expected *ValidationError, got %T: %v", err, err)
+	}
+	for _, want := range []string{`"outputs[0].adress" (did you mean "outputs[0].address"?)`, "outputs[0].address: required", "outputs[1].encoder"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Validation error should mention %q, got: %v", want, err)
+		}
+	}
+}
@@ -0,0 +1,283 @@
+package glog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// ErrNotModified is returned by ConfigSource.Load when the config has not
+// changed since the previous successful Load.
+var ErrNotModified = errors.New("config not modified")
+
+// ConfigSource provides the logger configuration, see WatchSource.
+//
+// Load returns the current config, or ErrNotModified if it is unchanged
+// since the previous successful Load. The returned Config is used as with
+// WithConfig, so environment variables, Build options and flags are still
+// applied on top of it.
+type ConfigSource interface {
+	Load(ctx context.Context) (*Config, error)
+}
+
+// FileSource loads the config from a YAML, JSON or TOML file, including its
+// includes and profiles, like WithConfigFile.
+type FileSource struct {
+	Path string
+	// Format overrides the format detected from the file extension.
+	Format string
+	// Profile selects a profile of the file; when empty the <prefix>_PROFILE
+	// environment variable is used, see WithProfile.
+	Profile string
+
+	mu sync.Mutex
+	// last are the files of the previous successful Load: the file itself
+	// and the files it includes.
+	last []loadedFile
+}
+
+// NewFileSource returns a FileSource for the file at path.
+func NewFileSource(path string) *FileSource {
+	return &FileSource{Path: path}
+}
+
+// Load reads the file and reports ErrNotModified if neither its content nor
+// that of the files it includes has changed.
+func (s *FileSource) Load(ctx context.Context) (*Config, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.last != nil && s.unchanged() {
+		return nil, ErrNotModified
+	}
+
+	cfg := &Config{SeparateLevels: true}
+	l, err := loadConfigFile(s.Path, s.Format, cfg, sourceProfile(s.Profile), strictMode.Load())
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	if len(l.unknown) > 0 {
+		return nil, &ValidationError{Problems: l.unknown}
+	}
+	s.last = l.files
+	return cfg, nil
+}
+
+// unchanged reports whether every file of the previous Load still has the
+// same content. s.mu must be held.
+func (s *FileSource) unchanged() bool {
+	for _, f := range s.last {
+		content, err := os.ReadFile(f.path)
+		if err != nil || !bytes.Equal(content, f.content) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *FileSource) String() string {
+	return s.Path
+}
+
+// EnvSource loads the config from environment variables only, named as
+// described in SetEnvPrefix, e.g. GLOG_LOG_LEVEL.
+type EnvSource struct {
+	// Prefix of the variables; when empty the package prefix is used.
+	Prefix string
+
+	mu   sync.Mutex
+	last *Config
+}
+
+// NewEnvSource returns an EnvSource for variables starting with prefix.
+func NewEnvSource(prefix string) *EnvSource {
+	return &EnvSource{Prefix: prefix}
+}
+
+// Load reads the environment and reports ErrNotModified if the resulting
+// config is unchanged.
+func (s *EnvSource) Load(ctx context.Context) (*Config, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prefix := s.Prefix
+	if prefix == "" {
+		prefix = getEnvPrefix()
+	}
+	cfg := &Config{SeparateLevels: true}
+	if err := applyEnv(cfg, prefix); err != nil {
+		return nil, fmt.Errorf("failed to apply environment overrides: %w", err)
+	}
+	if s.last != nil && reflect.DeepEqual(cfg, s.last) {
+		return nil, ErrNotModified
+	}
+	s.last = cfg
+	return cfg.clone(), nil
+}
+
+func (s *EnvSource) String() string {
+	return "environment"
+}
+
+// maxHTTPConfigSize limits the size of a config served over HTTP.
+const maxHTTPConfigSize = 1 << 20
+
+// HTTPSource loads the config from a URL. It sends the ETag of the previous
+// response as If-None-Match and treats 304 Not Modified, or an unchanged
+// body, as ErrNotModified.
+//
+// The format is taken from Format, else from the Content-Type of the
+// response, else from the extension of the URL path, defaulting to YAML.
+// Profiles are supported; includes are not resolved.
+type HTTPSource struct {
+	URL string
+	// Format overrides the format of the response body.
+	Format string
+	// Profile selects a profile, see FileSource.Profile.
+	Profile string
+	// Header is added to every request, e.g. for authentication.
+	Header http.Header
+	// Client sends the requests; http.DefaultClient is used when nil.
+	Client *http.Client
+
+	mu   sync.Mutex
+	etag string
+	last []byte
+}
+
+// NewHTTPSource returns an HTTPSource polling rawURL.
+func NewHTTPSource(rawURL string) *HTTPSource {
+	return &HTTPSource{URL: rawURL}
+}
+
+// Load fetches the config.
+func (s *HTTPSource) Load(ctx context.Context) (*Config, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range s.Header {
+		req.Header[key] = append([]string(nil), values...)
+	}
+	if s.etag != "" && s.last != nil {
+		req.Header.Set("If-None-Match", s.etag)
+	}
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, ErrNotModified
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s from %s", resp.Status, s.URL)
+	}
+	content, err := io.ReadAll(io.LimitReader(resp.Body, maxHTTPConfigSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config from %s: %w", s.URL, err)
+	}
+	if len(content) > maxHTTPConfigSize {
+		return nil, fmt.Errorf("config from %s exceeds %d bytes", s.URL, maxHTTPConfigSize)
+	}
+	if s.last != nil && bytes.Equal(content, s.last) {
+		s.etag = resp.Header.Get("ETag")
+		return nil, ErrNotModified
+	}
+
+	cfg, err := s.decode(content, resp.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config from %s: %w", s.URL, err)
+	}
+	s.etag, s.last = resp.Header.Get("ETag"), content
+	return cfg, nil
+}
+
+// decode parses a response body like readConfigFile parses a file.
+func (s *HTTPSource) decode(content []byte, contentType string) (*Config, error) {
+	format, err := s.format(contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &Config{SeparateLevels: true}
+	if err := unmarshalConfig(content, format, cfg); err != nil {
+		return nil, err
+	}
+	profile, strict := sourceProfile(s.Profile), strictMode.Load()
+	if profile == "" && !strict {
+		return cfg, nil
+	}
+
+	raw := map[string]interface{}{}
+	if err := unmarshalConfig(content, format, &raw); err != nil {
+		return nil, err
+	}
+	profiles, err := splitProfiles(raw)
+	if err != nil {
+		return nil, err
+	}
+	if strict {
+		if unknown := append(unknownKeys(raw, reflect.TypeOf(Config{}), ""), profileUnknownKeys(profiles)...); len(unknown) > 0 {
+			return nil, &ValidationError{Problems: unknown}
+		}
+	}
+	if profile != "" {
+		if err := applyProfile(cfg, profiles, profile); err != nil {
+			return nil, err
+		}
+	}
+	return cfg, nil
+}
+
+// format returns the format of a response with the given Content-Type.
+func (s *HTTPSource) format(contentType string) (string, error) {
+	if s.Format != "" {
+		return configFormat("", s.Format)
+	}
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		switch {
+		case strings.HasSuffix(mediaType, "json"):
+			return FormatJSON, nil
+		case strings.HasSuffix(mediaType, "toml"):
+			return FormatTOML, nil
+		case strings.HasSuffix(mediaType, "yaml"):
+			return FormatYAML, nil
+		}
+	}
+	path := s.URL
+	if u, err := url.Parse(s.URL); err == nil {
+		path = u.Path
+	}
+	return configFormat(path, "")
+}
+
+func (s *HTTPSource) String() string {
+	return s.URL
+}
+
+// sourceProfile returns profile or, if empty, the <prefix>_PROFILE variable.
+func sourceProfile(profile string) string {
+	if profile != "" {
+		return profile
+	}
+	return (&buildOptions{}).selectedProfile(getEnvPrefix())
+}
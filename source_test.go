@@ -0,0 +1,221 @@
+package glog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// configServer serves a config body with an ETag derived from its version.
+type configServer struct {
+	mu          sync.Mutex
+	body        string
+	contentType string
+	version     int
+	notModified int
+	// status, if set, is returned instead of the config.
+	status int
+}
+
+func (s *configServer) set(body string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.body = body
+	s.version++
+}
+
+func (s *configServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.status != 0 {
+		http.Error(w, http.StatusText(s.status), s.status)
+		return
+	}
+
+	etag := fmt.Sprintf(`"v%d"`, s.version)
+	if r.Header.Get("If-None-Match") == etag {
+		s.notModified++
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("ETag", etag)
+	if s.contentType != "" {
+		w.Header().Set("Content-Type", s.contentType)
+	}
+	fmt.Fprint(w, s.body)
+}
+
+func TestHTTPSourceETag(t *testing.T) {
+	cs := &configServer{}
+	cs.set("log_level: warn\nsegment:\n  max_size: 3\n")
+	ts := httptest.NewServer(cs)
+	defer ts.Close()
+
+	src := NewHTTPSource(ts.URL + "/logger")
+	src.Header = http.Header{"Authorization": {"Bearer token"}}
+
+	cfg, err := src.Load(context.Background())
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.LogLevel != "warn" || cfg.Segment.MaxSize != 3 || !cfg.SeparateLevels {
+		t.Errorf("Unexpected config %+v", cfg)
+	}
+
+	if _, err := src.Load(context.Background()); !errors.Is(err, ErrNotModified) {
+		t.Errorf("Expected ErrNotModified for an unchanged config, got %v", err)
+	}
+	if cs.notModified != 1 {
+		t.Errorf("Expected the ETag to be sent, got %d 304 responses", cs.notModified)
+	}
+
+	cs.mu.Lock()
+	cs.contentType = "application/json; charset=utf-8"
+	cs.mu.Unlock()
+	cs.set(`{"log_level": "debug"}`)
+	cfg, err = src.Load(context.Background())
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.LogLevel != "debug" {
+		t.Errorf("Expected the changed JSON config, got %+v", cfg)
+	}
+}
+
+func TestHTTPSourceErrors(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, ".toml") {
+			fmt.Fprint(w, "log_level: [not toml")
+			return
+		}
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	if _, err := NewHTTPSource(ts.URL).Load(context.Background()); err == nil || !strings.Contains(err.Error(), "500") {
+		t.Errorf("Expected a status error, got %v", err)
+	}
+	if _, err := NewHTTPSource(ts.URL + "/logger.toml").Load(context.Background()); err == nil {
+		t.Error("Expected a parse error for an invalid TOML body, got nil")
+	}
+}
+
+func TestFileAndEnvSourceNotModified(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "glog_test_sources")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	configPath := writeConfig(t, tempDir, profilesConfig)
+	fileSrc := NewFileSource(configPath)
+	fileSrc.Profile = "dev"
+	cfg, err := fileSrc.Load(context.Background())
+	if err != nil {
+		t.Fatalf("FileSource.Load failed: %v", err)
+	}
+	if cfg.LogLevel != "debug" {
+		t.Errorf("Expected the dev profile to be applied, got %+v", cfg)
+	}
+	if _, err := fileSrc.Load(context.Background()); !errors.Is(err, ErrNotModified) {
+		t.Errorf("Expected ErrNotModified from FileSource, got %v", err)
+	}
+
+	t.Setenv("MYAPP_LOG_LEVEL", "error")
+	envSrc := NewEnvSource("MYAPP")
+	if cfg, err := envSrc.Load(context.Background()); err != nil || cfg.LogLevel != "error" {
+		t.Fatalf("EnvSource.Load = %+v, %v", cfg, err)
+	}
+	if _, err := envSrc.Load(context.Background()); !errors.Is(err, ErrNotModified) {
+		t.Errorf("Expected ErrNotModified from EnvSource, got %v", err)
+	}
+	t.Setenv("MYAPP_LOG_LEVEL", "info")
+	if cfg, err := envSrc.Load(context.Background()); err != nil || cfg.LogLevel != "info" {
+		t.Errorf("EnvSource should report the changed variable, got %+v, %v", cfg, err)
+	}
+}
+
+func TestWatchEnvSourceUsesItsPrefix(t *testing.T) {
+	t.Setenv("MYAPP_LOG_LEVEL", "debug")
+	t.Setenv("GLOG_LOG_LEVEL", "error")
+
+	w, err := WatchSource(NewEnvSource("MYAPP"), time.Hour, nil, WithDirectory(t.TempDir()))
+	if err != nil {
+		t.Fatalf("WatchSource failed: %v", err)
+	}
+	defer w.Stop()
+	if got := CurrentConfig().LogLevel; got != "debug" {
+		t.Errorf("Expected the level of MYAPP_LOG_LEVEL, got %q", got)
+	}
+}
+
+func TestWatchSourceHTTP(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "glog_test_watch_http")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+	defer SetLevel(zap.InfoLevel)
+
+	cs := &configServer{}
+	cs.set(baseConsoleConfig + "log_level: info\n")
+	ts := httptest.NewServer(cs)
+	defer ts.Close()
+
+	results := make(chan error, 10)
+	w, err := WatchSource(NewHTTPSource(ts.URL), 10*time.Millisecond, func(err error) { results <- err }, WithDirectory(tempDir))
+	if err != nil {
+		t.Fatalf("WatchSource failed: %v", err)
+	}
+	defer w.Stop()
+
+	if CurrentConfig().Directory != tempDir {
+		t.Errorf("Options should be applied to the loaded config, got directory %q", CurrentConfig().Directory)
+	}
+	Debug("watch_http_debug_before_reload")
+
+	cs.set(baseConsoleConfig + "log_level: debug\n")
+	if err := waitReload(t, results); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	Debug("watch_http_debug_after_reload")
+	Flush() //nolint:errcheck
+
+	content, err := os.ReadFile(filepath.Join(tempDir, FileDebug))
+	if err != nil {
+		t.Fatalf("Failed to read debug log: %v", err)
+	}
+	if strings.Contains(string(content), "watch_http_debug_before_reload") {
+		t.Errorf("Debug message before reload should be filtered. Content: %s", content)
+	}
+	if !strings.Contains(string(content), "watch_http_debug_after_reload") {
+		t.Errorf("Debug message after reload should be written. Content: %s", content)
+	}
+
+	// A failing server is reported once and keeps the current logger.
+	stateBefore := getState()
+	cs.mu.Lock()
+	cs.status = http.StatusServiceUnavailable
+	cs.mu.Unlock()
+	if err := waitReload(t, results); err == nil {
+		t.Fatal("Expected a reload error for an unavailable server, got nil")
+	}
+	select {
+	case err := <-results:
+		t.Errorf("The same error should be reported only once, got another: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if getState() != stateBefore {
+		t.Error("A failed load should NOT change the global logger, but it did")
+	}
+}
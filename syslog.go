@@ -0,0 +1,257 @@
+package glog
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Syslog message formats, set with the "format" option of a syslog output.
+const (
+	SyslogRFC3164 = "rfc3164"
+	SyslogRFC5424 = "rfc5424"
+)
+
+// Framing of syslog messages on stream sockets (tcp, unix), set with the
+// "framing" option, see RFC 6587.
+const (
+	// SyslogOctetCounting prefixes every message with its length.
+	SyslogOctetCounting = "octet_counting"
+	// SyslogNonTransparent terminates every message with a newline.
+	SyslogNonTransparent = "non_transparent"
+)
+
+// syslogFacilities maps facility names to their codes.
+var syslogFacilities = map[string]int{
+	"kern": 0, "user": 1, "mail": 2, "daemon": 3, "auth": 4, "syslog": 5,
+	"lpr": 6, "news": 7, "uucp": 8, "cron": 9, "authpriv": 10, "ftp": 11,
+	"local0": 16, "local1": 17, "local2": 18, "local3": 19,
+	"local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+// syslogConfig is the resolved configuration of a syslog output.
+type syslogConfig struct {
+	format   string
+	framing  string
+	facility int
+	hostname string
+	appName  string
+	procID   string
+	msgID    string
+	// net configures the NetWriter that sends the messages.
+	net NetWriterConfig
+}
+
+// newSyslogConfig resolves the address and options of a syslog output:
+//
+//   - format: rfc3164 (default) or rfc5424
+//   - facility: a name such as daemon or local3, or a number, default user
+//   - app_name, procid: default to the program name and the process ID
+//   - hostname: defaults to os.Hostname
+//   - msgid: the MSGID of rfc5424 messages, default "-"
+//   - framing: octet_counting or non_transparent, for stream sockets only;
+//     defaults to octet_counting on tcp and non_transparent on unix sockets
+//   - buffer_size, min_backoff, max_backoff: see netWriterConfig
+func newSyslogConfig(out OutputConfig) (syslogConfig, error) {
+	network, address := syslogAddress(out.Address)
+	c := syslogConfig{
+		format:   SyslogRFC3164,
+		facility: syslogFacilities["user"],
+		appName:  filepath.Base(os.Args[0]),
+		procID:   strconv.Itoa(os.Getpid()),
+		msgID:    "-",
+	}
+	c.hostname, _ = os.Hostname()
+
+	opts := newOutputOptions(out.Options)
+	c.format = opts.stringOpt("format", SyslogRFC3164, SyslogRFC3164, SyslogRFC5424)
+	c.framing = opts.stringOpt("framing", "", SyslogOctetCounting, SyslogNonTransparent)
+	if v, ok := opts.lookup("facility"); ok {
+		facility, known := syslogFacilities[strings.ToLower(v)]
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 && n <= 23 {
+			facility, known = n, true
+		}
+		if known {
+			c.facility = facility
+		} else {
+			opts.invalid("facility", "unknown facility %q (want a name such as daemon or local0, or 0-23)", v)
+		}
+	}
+	c.appName = opts.stringOpt("app_name", c.appName)
+	c.procID = opts.stringOpt("procid", c.procID)
+	c.hostname = opts.stringOpt("hostname", c.hostname)
+	c.msgID = opts.stringOpt("msgid", c.msgID)
+	c.net = NetWriterConfig{
+		Network:    network,
+		Address:    address,
+		BufferSize: opts.intOpt("buffer_size", 0),
+		MinBackoff: opts.durationOpt("min_backoff", 0),
+		MaxBackoff: opts.durationOpt("max_backoff", 0),
+	}
+	return c, opts.err()
+}
+
+// syslogAddress splits an address like udp://host:514 into network and
+// address. A path is a unix datagram socket and host:port means udp.
+func syslogAddress(addr string) (network, address string) {
+	if n, a, ok := strings.Cut(addr, "://"); ok {
+		return n, a
+	}
+	if strings.HasPrefix(addr, "/") {
+		return "unixgram", addr
+	}
+	return "udp", addr
+}
+
+// syslogSeverity maps a level to a syslog severity. Panic and fatal are
+// critical (2): emergency (0) means the whole system is unusable and is
+// broadcast to every terminal by many syslog daemons.
+func syslogSeverity(level zapcore.Level) int {
+	switch {
+	case level <= zapcore.DebugLevel:
+		return 7
+	case level == zapcore.InfoLevel:
+		return 6
+	case level == zapcore.WarnLevel:
+		return 4
+	case level == zapcore.ErrorLevel:
+		return 3
+	}
+	return 2
+}
+
+// syslogSink sends entries to a syslog server, mapping levels to syslog
+// severities. Address is network://host:port, network:///path, host:port
+// (udp) or the path of a unix datagram socket; it defaults to the local
+// syslog daemon. Messages are sent by a NetWriter, so writes never block
+// and a lost connection is re-established with backoff.
+type syslogSink struct {
+	cfg syslogConfig
+	// framing is the framing of the connection, empty for datagram sockets.
+	framing string
+	w       *NetWriter
+}
+
+func newSyslogSink(out OutputConfig) (zap.Sink, error) {
+	cfg, err := newSyslogConfig(out)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.net.Address == "" {
+		if cfg.net.Network, cfg.net.Address, err = localSyslog(); err != nil {
+			return nil, err
+		}
+	}
+	w, err := NewNetWriter(cfg.net)
+	if err != nil {
+		return nil, err
+	}
+	return &syslogSink{cfg: cfg, framing: syslogFraming(cfg.net.Network, cfg.framing), w: w}, nil
+}
+
+// localSyslog finds the socket of the local syslog daemon.
+func localSyslog() (network, address string, err error) {
+	for _, network := range []string{"unixgram", "unix"} {
+		for _, path := range []string{"/dev/log", "/var/run/syslog", "/var/run/log"} {
+			var conn net.Conn
+			if conn, err = net.Dial(network, path); err == nil {
+				conn.Close()
+				return network, path, nil
+			}
+		}
+	}
+	return "", "", fmt.Errorf("no local syslog daemon found: %w", err)
+}
+
+// syslogFraming returns the framing of messages sent over network: framing
+// if set, else octet counting on tcp and newlines on unix stream sockets.
+// Datagram sockets send one message per datagram and need none.
+func syslogFraming(network, framing string) string {
+	switch network {
+	case "tcp", "tcp4", "tcp6":
+		if framing == "" {
+			return SyslogOctetCounting
+		}
+	case "unix":
+		if framing == "" {
+			return SyslogNonTransparent
+		}
+	default:
+		return ""
+	}
+	return framing
+}
+
+// format returns the framed syslog message of ent, with the encoded entry p
+// as MSG.
+func (s *syslogSink) format(ent zapcore.Entry, p []byte) []byte {
+	var msg bytes.Buffer
+	pri := s.cfg.facility*8 + syslogSeverity(ent.Level)
+	if s.cfg.format == SyslogRFC5424 {
+		fmt.Fprintf(&msg, "<%d>1 %s %s %s %s %s - ", pri,
+			ent.Time.Format("2006-01-02T15:04:05.000000Z07:00"),
+			syslogHeaderField(s.cfg.hostname, 255),
+			syslogHeaderField(s.cfg.appName, 48),
+			syslogHeaderField(s.cfg.procID, 128),
+			syslogHeaderField(s.cfg.msgID, 32))
+	} else {
+		fmt.Fprintf(&msg, "<%d>%s %s %s[%s]: ", pri,
+			ent.Time.Format(time.Stamp), s.cfg.hostname, s.cfg.appName, s.cfg.procID)
+	}
+	msg.Write(bytes.TrimRight(p, "\n"))
+
+	switch s.framing {
+	case SyslogOctetCounting:
+		return append([]byte(strconv.Itoa(msg.Len())+" "), msg.Bytes()...)
+	case SyslogNonTransparent:
+		msg.WriteByte('\n')
+	}
+	return msg.Bytes()
+}
+
+// syslogHeaderField makes v a valid RFC 5424 header field: printable
+// US-ASCII without spaces, at most max characters, "-" if empty.
+func syslogHeaderField(v string, max int) string {
+	if v == "" {
+		return "-"
+	}
+	b := []byte(v)
+	for i, c := range b {
+		if c < 33 || c > 126 {
+			b[i] = '_'
+		}
+	}
+	if len(b) > max {
+		b = b[:max]
+	}
+	return string(b)
+}
+
+func (s *syslogSink) WriteEntry(ent zapcore.Entry, _ []zapcore.Field, p []byte) error {
+	_, err := s.w.Write(s.format(ent, p))
+	return err
+}
+
+func (s *syslogSink) Write(p []byte) (int, error) {
+	if err := s.WriteEntry(zapcore.Entry{Level: zapcore.InfoLevel, Time: time.Now()}, nil, p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Sync waits until the pending messages have been sent, see NetWriter.Sync.
+func (s *syslogSink) Sync() error {
+	return s.w.Sync()
+}
+
+func (s *syslogSink) Close() error {
+	return s.w.Close()
+}
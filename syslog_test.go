@@ -0,0 +1,247 @@
+package glog
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// readDatagram reads one message from pc.
+func readDatagram(t *testing.T, pc net.PacketConn) string {
+	t.Helper()
+	buf := make([]byte, 4096)
+	pc.SetReadDeadline(time.Now().Add(5 * time.Second)) //nolint:errcheck
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("Failed to read syslog message: %v", err)
+	}
+	return string(buf[:n])
+}
+
+func TestSyslogRFC5424OverUDP(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("ListenPacket failed: %v", err)
+	}
+	defer pc.Close()
+
+	logger, err := Build(WithConfig(&Config{
+		Encoder: "json",
+		Outputs: []OutputConfig{{
+			Type:    "syslog",
+			Address: "udp://" + pc.LocalAddr().String(),
+			Options: map[string]string{
+				"format":   "rfc5424",
+				"facility": "local3",
+				"app_name": "my app",
+				"procid":   "worker-1",
+				"hostname": "web01",
+				"msgid":    "REQ",
+			},
+		}},
+	}))
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	logger.Warn("syslog_5424_message")
+
+	// facility local3 (19) * 8 + severity warning (4)
+	re := regexp.MustCompile(`^<156>1 \d{4}-\d\d-\d\dT\d\d:\d\d:\d\d\.\d{6}(Z|[+-]\d\d:\d\d) web01 my_app worker-1 REQ - \{.*"message":"syslog_5424_message".*\}$`)
+	if msg := readDatagram(t, pc); !re.MatchString(msg) {
+		t.Errorf("Unexpected RFC 5424 message %q", msg)
+	}
+}
+
+func TestSyslogOctetCountingOverTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	defer ln.Close()
+
+	frames := make(chan string, 10)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		for {
+			length, err := r.ReadString(' ')
+			if err != nil {
+				return
+			}
+			n, err := strconv.Atoi(strings.TrimSpace(length))
+			if err != nil {
+				frames <- "bad length " + length
+				return
+			}
+			frame := make([]byte, n)
+			if _, err := io.ReadFull(r, frame); err != nil {
+				return
+			}
+			frames <- string(frame)
+		}
+	}()
+
+	logger, err := Build(WithConfig(&Config{
+		Encoder: "console",
+		Outputs: []OutputConfig{{
+			Type:    "syslog",
+			Address: "tcp://" + ln.Addr().String(),
+			Options: map[string]string{"format": "rfc5424", "facility": "daemon", "app_name": "app", "procid": "42"},
+		}},
+	}))
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	// The second message contains a newline, which octet counting preserves.
+	logger.Info("first_tcp_message")
+	logger.Error("second_tcp_message\nwith a second line")
+
+	for _, want := range []struct{ pri, msg string }{
+		{"<30>1 ", "first_tcp_message"},                 // daemon (3) * 8 + info (6)
+		{"<27>1 ", "second_tcp_message\nwith a second"}, // daemon (3) * 8 + error (3)
+	} {
+		select {
+		case frame := <-frames:
+			if !strings.HasPrefix(frame, want.pri) || !strings.Contains(frame, " app 42 - - ") || !strings.Contains(frame, want.msg) {
+				t.Errorf("Unexpected frame %q, want prefix %q and message %q", frame, want.pri, want.msg)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timed out waiting for a syslog frame")
+		}
+	}
+}
+
+func TestSyslogReconnectsInBackground(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	logger, err := Build(WithConfig(&Config{
+		Encoder: "console",
+		Outputs: []OutputConfig{{
+			Type:    "syslog",
+			Address: "tcp://" + addr,
+			Options: map[string]string{"min_backoff": "10ms", "max_backoff": "50ms"},
+		}},
+	}))
+	if err != nil {
+		t.Fatalf("Build should not need the server to be up: %v", err)
+	}
+	defer logger.Close()
+
+	start := time.Now()
+	logger.Info("syslog_while_down")
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("Logging while the server is down took %v", d)
+	}
+
+	if ln, err = net.Listen("tcp", addr); err != nil {
+		t.Skipf("Cannot listen on %s again: %v", addr, err)
+	}
+	defer ln.Close()
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatalf("Accept failed: %v", err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second)) //nolint:errcheck
+	r := bufio.NewReader(conn)
+	length, err := r.ReadString(' ')
+	if err != nil {
+		t.Fatalf("Failed to read the buffered message: %v", err)
+	}
+	n, _ := strconv.Atoi(strings.TrimSpace(length))
+	frame := make([]byte, n)
+	if _, err := io.ReadFull(r, frame); err != nil {
+		t.Fatalf("Failed to read the buffered message: %v", err)
+	}
+	if !strings.Contains(string(frame), "syslog_while_down") {
+		t.Errorf("Unexpected message %q", frame)
+	}
+}
+
+func TestSyslogRFC3164OverUnixSocket(t *testing.T) {
+	dir, err := os.MkdirTemp("", "glog_test_syslog")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "log.sock")
+	pc, err := net.ListenPacket("unixgram", path)
+	if err != nil {
+		t.Skipf("unixgram sockets are not supported: %v", err)
+	}
+	defer pc.Close()
+
+	logger, err := Build(WithConfig(&Config{
+		Encoder:  "console",
+		LogLevel: "debug",
+		Outputs: []OutputConfig{{
+			Type:    "syslog",
+			Address: path,
+			Options: map[string]string{"app_name": "glogtest", "procid": "7", "hostname": "host1"},
+		}},
+	}))
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	logger.Debug("syslog_unix_message")
+
+	// facility user (1) * 8 + severity debug (7)
+	re := regexp.MustCompile(`^<15>[A-Z][a-z]{2} [ \d]\d \d\d:\d\d:\d\d host1 glogtest\[7\]: .*syslog_unix_message$`)
+	if msg := readDatagram(t, pc); !re.MatchString(msg) {
+		t.Errorf("Unexpected RFC 3164 message %q", msg)
+	}
+}
+
+func TestSyslogSeverity(t *testing.T) {
+	for level, want := range map[zapcore.Level]int{
+		zap.DebugLevel:  7,
+		zap.InfoLevel:   6,
+		zap.WarnLevel:   4,
+		zap.ErrorLevel:  3,
+		zap.DPanicLevel: 2,
+		zap.PanicLevel:  2,
+		zap.FatalLevel:  2,
+	} {
+		if got := syslogSeverity(level); got != want {
+			t.Errorf("syslogSeverity(%s) = %d, want %d", level, got, want)
+		}
+	}
+}
+
+func TestSyslogOptionErrors(t *testing.T) {
+	for _, opts := range []map[string]string{
+		{"format": "rfc1234"},
+		{"facility": "local9"},
+		{"facility": "24"},
+		{"framing": "newline"},
+		{"app": "typo"},
+	} {
+		out := OutputConfig{Type: "syslog", Address: "udp://127.0.0.1:514", Options: opts}
+		if _, err := newSyslogConfig(out); err == nil {
+			t.Errorf("Expected an error for options %v, got nil", opts)
+		}
+		if problems := out.validate("outputs[0]"); len(problems) != 1 || !strings.HasPrefix(problems[0], "outputs[0].options: ") {
+			t.Errorf("Expected one options problem for %v, got %q", opts, problems)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package glog
+
+import (
+	"context"
+	"encoding/hex"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// Keys of the trace fields added by the context-aware functions.
+const (
+	TraceIDKey    = "trace_id"
+	SpanIDKey     = "span_id"
+	TraceFlagsKey = "trace_flags"
+)
+
+// SpanContext identifies the active span of a request, as returned by a
+// SpanContextFunc. Its layout matches trace.SpanContext of OpenTelemetry.
+type SpanContext struct {
+	TraceID    [16]byte
+	SpanID     [8]byte
+	TraceFlags byte
+}
+
+// IsValid reports whether sc has a non-zero trace and span ID.
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID != [16]byte{} && sc.SpanID != [8]byte{}
+}
+
+// SpanContextFunc returns the active span of ctx, false if there is none.
+type SpanContextFunc func(ctx context.Context) (SpanContext, bool)
+
+var spanContextFunc atomic.Value // of SpanContextFunc
+
+// SetSpanContextFunc overrides how the context-aware functions find the
+// active span of a context, e.g. for a tracer other than OpenTelemetry; nil
+// restores the default, the OpenTelemetry span of the context.
+//
+// 示例：
+//
+//	glog.SetSpanContextFunc(func(ctx context.Context) (glog.SpanContext, bool) {
+//		sc, ok := mytracer.FromContext(ctx)
+//		return glog.SpanContext{TraceID: sc.TraceID, SpanID: sc.SpanID}, ok
+//	})
+func SetSpanContextFunc(fn SpanContextFunc) {
+	spanContextFunc.Store(fn)
+}
+
+// SpanContextFromContext returns the active span of ctx: the OpenTelemetry
+// span (see trace.SpanContextFromContext), or the one returned by the
+// function set with SetSpanContextFunc.
+func SpanContextFromContext(ctx context.Context) (SpanContext, bool) {
+	if ctx == nil {
+		return SpanContext{}, false
+	}
+	if fn, _ := spanContextFunc.Load().(SpanContextFunc); fn != nil {
+		sc, ok := fn(ctx)
+		return sc, ok && sc.IsValid()
+	}
+	sc := trace.SpanContextFromContext(ctx)
+	return SpanContext{
+		TraceID:    sc.TraceID(),
+		SpanID:     sc.SpanID(),
+		TraceFlags: byte(sc.TraceFlags()),
+	}, sc.IsValid()
+}
+
+// TraceFields returns the trace_id, span_id and trace_flags fields of the
+// active span of ctx, in the W3C Trace Context hex form, or nil if there is
+// no span.
+func TraceFields(ctx context.Context) []zap.Field {
+	sc, ok := SpanContextFromContext(ctx)
+	if !ok {
+		return nil
+	}
+	return []zap.Field{
+		zap.String(TraceIDKey, hex.EncodeToString(sc.TraceID[:])),
+		zap.String(SpanIDKey, hex.EncodeToString(sc.SpanID[:])),
+		zap.String(TraceFlagsKey, hex.EncodeToString([]byte{sc.TraceFlags})),
+	}
+}
+
+// withTrace adds the trace fields of ctx to logger.
+func withTrace(logger *zap.SugaredLogger, ctx context.Context) *zap.SugaredLogger {
+	fields := TraceFields(ctx)
+	if fields == nil {
+		return logger
+	}
+	return logger.Desugar().With(fields...).Sugar()
+}
+
+// WithContext returns the global logger with the trace fields of ctx, see
+// TraceFields. Like Named, it follows Init and reloads.
+//
+// 示例：
+//
+//	log := glog.WithContext(ctx)
+//	log.Infow("order created", "order_id", id)
+func WithContext(ctx context.Context) *zap.SugaredLogger {
+	return withTrace(followGlobal(), ctx)
+}
+
+// WithContext returns l with the trace fields of ctx, see TraceFields.
+func (l *Logger) WithContext(ctx context.Context) *zap.SugaredLogger {
+	return withTrace(l.SugaredLogger, ctx)
+}
+
+// contextLogger is the logger of the package-level context functions.
+func (s *loggerState) contextLogger(ctx context.Context) *zap.SugaredLogger {
+	logger := s.logger
+	if s.showGoroutine {
+		logger = logger.With("goroutine", getGoroutineID())
+	}
+	return withTrace(logger, ctx)
+}
+
+// DebugContext logs msg and the key-value pairs at Debug level, with the
+// trace fields of ctx.
+func DebugContext(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	if s := getState(); s != nil && s.logger != nil {
+		if !s.logger.Desugar().Core().Enabled(zap.DebugLevel) {
+			return
+		}
+		s.contextLogger(ctx).Debugw(msg, keysAndValues...)
+	}
+}
+
+// InfoContext logs msg and the key-value pairs at Info level, with the
+// trace fields of ctx.
+func InfoContext(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	if s := getState(); s != nil && s.logger != nil {
+		if !s.logger.Desugar().Core().Enabled(zap.InfoLevel) {
+			return
+		}
+		s.contextLogger(ctx).Infow(msg, keysAndValues...)
+	}
+}
+
+// WarnContext logs msg and the key-value pairs at Warn level, with the
+// trace fields of ctx.
+func WarnContext(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	if s := getState(); s != nil && s.logger != nil {
+		if !s.logger.Desugar().Core().Enabled(zap.WarnLevel) {
+			return
+		}
+		s.contextLogger(ctx).Warnw(msg, keysAndValues...)
+	}
+}
+
+// ErrorContext logs msg and the key-value pairs at Error level, with the
+// trace fields of ctx.
+func ErrorContext(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	if s := getState(); s != nil && s.logger != nil {
+		if !s.logger.Desugar().Core().Enabled(zap.ErrorLevel) {
+			return
+		}
+		s.contextLogger(ctx).Errorw(msg, keysAndValues...)
+	}
+}
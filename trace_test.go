@@ -0,0 +1,150 @@
+package glog
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+var testSpan = SpanContext{
+	TraceID:    [16]byte{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+	SpanID:     [8]byte{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+	TraceFlags: 1,
+}
+
+// otelContext returns a context with testSpan as its OpenTelemetry span.
+func otelContext() context.Context {
+	return trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    testSpan.TraceID,
+		SpanID:     testSpan.SpanID,
+		TraceFlags: trace.FlagsSampled,
+	}))
+}
+
+func TestContextFunctions(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "glog_test_trace")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	configPath := writeConfig(t, tempDir, `
+encoder: json
+show_line: true
+log_level: info
+separate_levels: false
+`)
+	if err := Init(configPath, tempDir); err != nil {
+		t.Fatalf("Failed to initialize logger: %v", err)
+	}
+
+	ctx := otelContext()
+	InfoContext(ctx, "trace_info", "order_id", 42)
+	DebugContext(ctx, "trace_debug_filtered")
+	WarnContext(context.Background(), "trace_without_span")
+	WithContext(ctx).Errorw("trace_with_context")
+	Flush() //nolint:errcheck
+
+	content, err := os.ReadFile(filepath.Join(tempDir, "app.log"))
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	entries := map[string]map[string]interface{}{}
+	for _, line := range strings.Split(strings.TrimSpace(string(content)), "\n") {
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("Invalid JSON line %q: %v", line, err)
+		}
+		entries[entry["message"].(string)] = entry
+	}
+
+	if _, ok := entries["trace_debug_filtered"]; ok {
+		t.Error("DebugContext should follow the level")
+	}
+	for _, msg := range []string{"trace_info", "trace_with_context"} {
+		entry := entries[msg]
+		if entry[TraceIDKey] != "4bf92f3577b34da6a3ce929d0e0e4736" || entry[SpanIDKey] != "00f067aa0ba902b7" || entry[TraceFlagsKey] != "01" {
+			t.Errorf("%s: expected trace fields, got %v", msg, entry)
+		}
+		if caller, _ := entry["caller"].(string); !strings.Contains(caller, "trace_test.go") {
+			t.Errorf("%s: expected the caller in trace_test.go, got %q", msg, caller)
+		}
+	}
+	if entries["trace_info"]["order_id"] != float64(42) {
+		t.Errorf("Expected the key-value pairs, got %v", entries["trace_info"])
+	}
+	if _, ok := entries["trace_without_span"][TraceIDKey]; ok || entries["trace_without_span"] == nil {
+		t.Errorf("Entries without a span should have no trace fields, got %v", entries["trace_without_span"])
+	}
+}
+
+func TestSetSpanContextFunc(t *testing.T) {
+	type spanKey struct{}
+	SetSpanContextFunc(func(ctx context.Context) (SpanContext, bool) {
+		sc, ok := ctx.Value(spanKey{}).(SpanContext)
+		return sc, ok
+	})
+	t.Cleanup(func() { SetSpanContextFunc(nil) })
+
+	var buf strings.Builder
+	logger, err := Build(WithConfig(&Config{Encoder: "json", Directory: t.TempDir()}), WithWriter(&buf))
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	logger.WithContext(otelContext()).Info("trace_otel_ignored")
+	logger.WithContext(context.WithValue(context.Background(), spanKey{}, testSpan)).Info("trace_custom")
+	logger.WithContext(context.WithValue(context.Background(), spanKey{}, SpanContext{})).Info("trace_invalid")
+	logger.Sync() //nolint:errcheck
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 lines, got %q", lines)
+	}
+	if strings.Contains(lines[0], TraceIDKey) || strings.Contains(lines[2], TraceIDKey) {
+		t.Errorf("Only the span returned by the function should be used, got %q", lines)
+	}
+	if !strings.Contains(lines[1], `"trace_id":"4bf92f3577b34da6a3ce929d0e0e4736"`) {
+		t.Errorf("Expected the trace ID, got %s", lines[1])
+	}
+
+	SetSpanContextFunc(nil)
+	if _, ok := SpanContextFromContext(otelContext()); !ok {
+		t.Error("SetSpanContextFunc(nil) should restore the default")
+	}
+}
+
+func TestOTLPRecordTraceContext(t *testing.T) {
+	s := &otlpSink{format: OTLPJSON}
+	s.batcher = newBatcher(batchConfig{size: 10, bytes: 1 << 20, delay: time.Hour, maxPending: 1}, func([]batchItem) error { return nil }, func(error) {})
+	defer s.close() //nolint:errcheck
+
+	fields := append(TraceFields(otelContext()), zap.String("user", "alice"))
+	if err := s.WriteEntry(zapcore.Entry{Time: time.Now(), Message: "traced"}, fields, nil); err != nil {
+		t.Fatalf("WriteEntry failed: %v", err)
+	}
+
+	var rec struct {
+		TraceID    string            `json:"traceId"`
+		SpanID     string            `json:"spanId"`
+		Flags      uint32            `json:"flags"`
+		Attributes []json.RawMessage `json:"attributes"`
+	}
+	if err := json.Unmarshal(s.current[0].data, &rec); err != nil {
+		t.Fatalf("Invalid record: %v", err)
+	}
+	if rec.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || rec.SpanID != "00f067aa0ba902b7" || rec.Flags != 1 {
+		t.Errorf("Expected the trace context on the record, got %+v", rec)
+	}
+	if len(rec.Attributes) != 1 {
+		t.Errorf("Trace fields should not be attributes, got %s", rec.Attributes)
+	}
+}
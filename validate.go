@@ -0,0 +1,290 @@
+package glog
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// strictMode makes Init, New and NewLogger refuse to start on an invalid config.
+var strictMode atomic.Bool
+
+// SetStrict enables or disables strict mode for Init, New, NewLogger and Build.
+//
+// In strict mode unknown keys in the config file and every problem reported
+// by Config.Validate are turned into a *ValidationError instead of being
+// silently replaced by defaults.
+func SetStrict(enabled bool) {
+	strictMode.Store(enabled)
+}
+
+// WithStrict enables strict mode for a single Build, see SetStrict.
+func WithStrict() Option {
+	return func(o *buildOptions) {
+		o.strict = true
+	}
+}
+
+// ValidationError lists every problem found in a logger configuration.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid logger config: " + strings.Join(e.Problems, "; ")
+}
+
+// Validate checks the config and reports all problems at once as a
+// *ValidationError. Empty values are valid because setDefaults fills them in.
+func (c *Config) Validate() error {
+	var problems []string
+
+	if c.LogLevel != "" {
+		if _, err := parseLevel(c.LogLevel); err != nil {
+			problems = append(problems, fmt.Sprintf("log_level: %v", err))
+		}
+	}
+
+	names := make([]string, 0, len(c.Levels))
+	for name := range c.Levels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if name == "" {
+			problems = append(problems, "levels: logger name must not be empty")
+			continue
+		}
+		if _, err := parseLevel(c.Levels[name]); err != nil {
+			problems = append(problems, fmt.Sprintf("levels.%s: %v", name, err))
+		}
+	}
+
+	if c.Verbosity < 0 {
+		problems = append(problems, fmt.Sprintf("verbosity: must not be negative, got %d", c.Verbosity))
+	}
+	if _, err := parseVModule(c.VModule); err != nil {
+		problems = append(problems, fmt.Sprintf("vmodule: %v", err))
+	}
+
+	for _, e := range []struct{ key, value string }{
+		{"encoder", c.Encoder},
+		{"console_encoder", c.ConsoleEncoder},
+	} {
+		switch e.value {
+		case "", "json", "console":
+		default:
+			problems = append(problems, fmt.Sprintf("%s: unknown encoder %q (want json or console)", e.key, e.value))
+		}
+	}
+
+	for _, e := range []struct{ key, value string }{
+		{"encode_level", c.EncodeLevel},
+		{"console_encode_level", c.ConsoleEncodeLevel},
+	} {
+		switch e.value {
+		case "", LowercaseLevelEncoder, LowercaseColorLevelEncoder, CapitalLevelEncoder, CapitalColorLevelEncoder:
+		default:
+			problems = append(problems, fmt.Sprintf("%s: unknown level encoder %q (want %s, %s, %s or %s)",
+				e.key, e.value, LowercaseLevelEncoder, LowercaseColorLevelEncoder, CapitalLevelEncoder, CapitalColorLevelEncoder))
+		}
+	}
+
+	for _, s := range []struct {
+		key   string
+		value int
+	}{
+		{"segment.max_size", c.Segment.MaxSize},
+		{"segment.max_age", c.Segment.MaxAge},
+		{"segment.max_backups", c.Segment.MaxBackups},
+	} {
+		if s.value < 0 {
+			problems = append(problems, fmt.Sprintf("%s: must not be negative, got %d", s.key, s.value))
+		}
+	}
+
+	problems = append(problems, c.Async.validate("async")...)
+
+	for i, out := range c.Outputs {
+		problems = append(problems, out.validate(fmt.Sprintf("outputs[%d]", i))...)
+	}
+
+	if c.StderrOnly || (len(c.Outputs) > 0 && c.Path+c.Directory == "") {
+		// No log files are written, or only to the absolute paths of outputs.
+	} else if err := checkWritableDir(c.Path + c.Directory); err != nil {
+		problems = append(problems, fmt.Sprintf("path/directory: %v", err))
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// checkWritableDir reports whether log files can be created in dir. If dir
+// does not exist yet, its closest existing parent must be writable.
+func checkWritableDir(dir string) error {
+	if dir == "" {
+		return errors.New("log directory is empty")
+	}
+
+	existing := dir
+	for {
+		info, err := os.Stat(existing)
+		if err == nil {
+			if !info.IsDir() {
+				return fmt.Errorf("%s is not a directory", existing)
+			}
+			break
+		}
+		if !os.IsNotExist(err) {
+			return err
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			return fmt.Errorf("no existing parent directory for %s", dir)
+		}
+		existing = parent
+	}
+
+	f, err := os.CreateTemp(existing, ".glog-write-check-*")
+	if err != nil {
+		return fmt.Errorf("%s is not writable: %w", existing, err)
+	}
+	name := f.Name()
+	f.Close()
+	os.Remove(name)
+	return nil
+}
+
+// unknownKeys returns the dotted paths of keys in raw that do not match a
+// yaml tag of t, with a suggestion when a known key is close enough.
+func unknownKeys(raw map[string]interface{}, t reflect.Type, prefix string) []string {
+	known := make(map[string]reflect.Type, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		if name := yamlName(t.Field(i)); name != "" {
+			known[name] = t.Field(i).Type
+		}
+	}
+
+	keys := make([]string, 0, len(raw))
+	for key := range raw {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var problems []string
+	for _, key := range keys {
+		fieldType, ok := known[key]
+		if !ok {
+			msg := fmt.Sprintf("unknown key %q", prefix+key)
+			if s := suggestKey(key, known); s != "" {
+				msg += fmt.Sprintf(" (did you mean %q?)", prefix+s)
+			}
+			problems = append(problems, msg)
+			continue
+		}
+		if nested, ok := raw[key].(map[string]interface{}); ok && fieldType.Kind() == reflect.Struct {
+			problems = append(problems, unknownKeys(nested, fieldType, prefix+key+".")...)
+		}
+		if items, ok := raw[key].([]interface{}); ok && fieldType.Kind() == reflect.Slice && fieldType.Elem().Kind() == reflect.Struct {
+			for i, item := range items {
+				if nested, ok := item.(map[string]interface{}); ok {
+					problems = append(problems, unknownKeys(nested, fieldType.Elem(), fmt.Sprintf("%s%s[%d].", prefix, key, i))...)
+				}
+			}
+		}
+	}
+	return problems
+}
+
+// suggestKey returns the known key closest to key if it is within two edits.
+func suggestKey(key string, known map[string]reflect.Type) string {
+	best, bestDist := "", 3
+	for candidate := range known {
+		if d := editDistance(key, candidate); d < bestDist || (d == bestDist && candidate < best) {
+			best, bestDist = candidate, d
+		}
+	}
+	return best
+}
+
+// editDistance computes the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// validate reports the problems of an output, prefixed with name.
+func (o OutputConfig) validate(name string) []string {
+	var problems []string
+	if _, ok := sinkFactory(o.Type); !ok {
+		problems = append(problems, fmt.Sprintf("%s.type: unknown output type %q (registered: %s)", name, o.Type, strings.Join(sinkNames(), ", ")))
+	}
+	if o.Level != "" {
+		if _, err := parseLevel(o.Level); err != nil {
+			problems = append(problems, fmt.Sprintf("%s.level: %v", name, err))
+		}
+	}
+	switch o.Encoder {
+	case "", "json", "console":
+	default:
+		problems = append(problems, fmt.Sprintf("%s.encoder: unknown encoder %q (want json or console)", name, o.Encoder))
+	}
+	switch o.EncodeLevel {
+	case "", LowercaseLevelEncoder, LowercaseColorLevelEncoder, CapitalLevelEncoder, CapitalColorLevelEncoder:
+	default:
+		problems = append(problems, fmt.Sprintf("%s.encode_level: unknown level encoder %q", name, o.EncodeLevel))
+	}
+	switch o.Type {
+	case "tcp", "udp", "unix", "unixgram":
+		if o.Address == "" {
+			problems = append(problems, fmt.Sprintf("%s.address: required for %s outputs", name, o.Type))
+		} else if _, err := netWriterConfig(o); err != nil {
+			problems = append(problems, fmt.Sprintf("%s.options: %v", name, err))
+		}
+	case "http":
+		if o.URL == "" {
+			problems = append(problems, fmt.Sprintf("%s.url: required for http outputs", name))
+		} else if _, _, err := parseHTTPOutput(o); err != nil {
+			problems = append(problems, fmt.Sprintf("%s.options: %v", name, err))
+		}
+	case "loki":
+		if o.URL == "" {
+			problems = append(problems, fmt.Sprintf("%s.url: required for loki outputs", name))
+		} else if _, _, err := parseLokiOutput(o); err != nil {
+			problems = append(problems, fmt.Sprintf("%s.options: %v", name, err))
+		}
+	case "otlp":
+		if o.URL == "" {
+			problems = append(problems, fmt.Sprintf("%s.url: required for otlp outputs", name))
+		} else if _, _, err := parseOTLPOutput(o); err != nil {
+			problems = append(problems, fmt.Sprintf("%s.options: %v", name, err))
+		}
+	case "syslog":
+		if _, err := newSyslogConfig(o); err != nil {
+			problems = append(problems, fmt.Sprintf("%s.options: %v", name, err))
+		}
+	}
+	return problems
+}
@@ -0,0 +1,161 @@
+package glog
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateValidConfig(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "glog_test_validate_ok")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	cfg := &Config{
+		Encoder:     "json",
+		EncodeLevel: CapitalColorLevelEncoder,
+		LogLevel:    "WARNING",
+		Directory:   filepath.Join(tempDir, "not", "yet", "created"),
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected valid config, got: %v", err)
+	}
+}
+
+func TestValidateReportsAllProblems(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "glog_test_validate_bad")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// A regular file cannot be used as log directory.
+	notDir := filepath.Join(tempDir, "file")
+	if err := os.WriteFile(notDir, nil, 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	cfg := &Config{
+		Encoder:     "jsno",
+		EncodeLevel: "Rainbow",
+		LogLevel:    "debg",
+		Directory:   notDir,
+		Segment:     Segment{MaxSize: -1, MaxAge: -2, MaxBackups: -3},
+	}
+	err = cfg.Validate()
+
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Expected *ValidationError, got %T: %v", err, err)
+	}
+	for _, want := range []string{
+		"log_level", "encoder", "encode_level",
+		"segment.max_size", "segment.max_age", "segment.max_backups",
+		"not a directory",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Validation error should mention %q, got: %v", want, err)
+		}
+	}
+	if len(verr.Problems) != 7 {
+		t.Errorf("Expected 7 problems, got %d: %v", len(verr.Problems), verr.Problems)
+	}
+}
+
+func TestValidateEmptyDirectory(t *testing.T) {
+	if err := (&Config{}).Validate(); err == nil {
+		t.Error("Expected an error for an empty log directory, got nil")
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	if _, err := parseLevel("Warning"); err != nil {
+		t.Errorf("parseLevel(Warning) failed: %v", err)
+	}
+	if _, err := parseLevel("debg"); err == nil {
+		t.Error("Expected an error for unknown level, got nil")
+	}
+}
+
+func TestUnknownKeys(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "glog_test_unknown_keys")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	configPath := writeConfig(t, tempDir, `
+log_levle: debug
+encoder: json
+segment:
+  max_sise: 10
+  whatever: true
+`)
+	cfg := &Config{}
+	unknown, err := readConfigFile(configPath, "", cfg, "", true)
+	if err != nil {
+		t.Fatalf("readConfigFile failed: %v", err)
+	}
+
+	want := []string{
+		`unknown key "log_levle" (did you mean "log_level"?)`,
+		`unknown key "segment.max_sise" (did you mean "segment.max_size"?)`,
+		`unknown key "segment.whatever"`,
+	}
+	if strings.Join(unknown, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Unexpected unknown keys.\nGot:  %q\nWant: %q", unknown, want)
+	}
+	if cfg.Encoder != "json" {
+		t.Errorf("Known keys should still be decoded, got Encoder=%q", cfg.Encoder)
+	}
+}
+
+func TestStrictModeRejectsInvalidConfig(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "glog_test_strict")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	configPath := writeConfig(t, tempDir, `
+log_levle: debug
+log_level: debg
+encoder: jsno
+`)
+
+	// Without strict mode typos fall back to defaults.
+	if _, err := New(configPath, tempDir); err != nil {
+		t.Fatalf("New without strict mode failed: %v", err)
+	}
+
+	SetStrict(true)
+	defer SetStrict(false)
+
+	stateBefore := getState()
+	err = Init(configPath, tempDir)
+
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Expected *ValidationError in strict mode, got %T: %v", err, err)
+	}
+	if len(verr.Problems) != 3 {
+		t.Errorf("Expected 3 problems, got %d: %v", len(verr.Problems), verr.Problems)
+	}
+	if getState() != stateBefore {
+		t.Error("Init in strict mode with invalid config should NOT change the global logger, but it did")
+	}
+}
+
+func TestWithStrict(t *testing.T) {
+	_, err := Build(WithConfig(&Config{LogLevel: "verbose"}), WithStrict())
+	if err == nil {
+		t.Fatal("Expected an error from Build with WithStrict, got nil")
+	}
+	if !strings.Contains(err.Error(), "log_level") {
+		t.Errorf("Error should mention log_level, got: %v", err)
+	}
+}
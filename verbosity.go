@@ -0,0 +1,193 @@
+package glog
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// Verbose is returned by V. Its methods log at Debug level when the
+// verbosity check passed and do nothing otherwise, so both forms work:
+//
+//	glog.V(2).Infof("cache miss for %s", key)
+//
+//	if glog.V(3) {
+//		glog.V(3).Info(expensiveDump())
+//	}
+type Verbose bool
+
+// V reports whether verbose logging at level is enabled for the caller.
+//
+// V-level entries are written at Debug level, so they need both the debug
+// level enabled (log_level: debug) and a verbosity of at least level, taken
+// from the vmodule pattern matching the caller's source file or, failing
+// that, from Config.Verbosity. V(0) is therefore equivalent to Debug.
+//
+// A disabled V costs an atomic load and an integer comparison unless a
+// vmodule pattern allows a higher level; the result per call site is then
+// cached, so only its first call resolves the caller's file.
+func V(level int) Verbose {
+	s := getState()
+	v := s.verbosity
+	if v == nil {
+		if level > 0 {
+			return false
+		}
+	} else if level > v.max {
+		return false
+	} else if level > v.level {
+		var pcs [1]uintptr
+		if runtime.Callers(2, pcs[:]) == 0 || level > v.forPC(pcs[0]) {
+			return false
+		}
+	}
+	return Verbose(s.logger.Desugar().Core().Enabled(zap.DebugLevel))
+}
+
+// Info logs args at Debug level if v is enabled.
+func (v Verbose) Info(args ...interface{}) {
+	if !v {
+		return
+	}
+	s := getState()
+	if s.showGoroutine {
+		s.logger.With("goroutine", getGoroutineID()).Debug(args...)
+	} else {
+		s.logger.Debug(args...)
+	}
+}
+
+// Infof logs a formatted message at Debug level if v is enabled.
+func (v Verbose) Infof(template string, args ...interface{}) {
+	if !v {
+		return
+	}
+	s := getState()
+	if s.showGoroutine {
+		s.logger.With("goroutine", getGoroutineID()).Debugf(template, args...)
+	} else {
+		s.logger.Debugf(template, args...)
+	}
+}
+
+// Infow logs a message with key-value pairs at Debug level if v is enabled.
+func (v Verbose) Infow(msg string, keysAndValues ...interface{}) {
+	if !v {
+		return
+	}
+	s := getState()
+	if s.showGoroutine {
+		s.logger.With("goroutine", getGoroutineID()).Debugw(msg, keysAndValues...)
+	} else {
+		s.logger.Debugw(msg, keysAndValues...)
+	}
+}
+
+// verbosity holds the resolved Verbosity and VModule of the global logger.
+type verbosity struct {
+	level int
+	// max is the highest level any call site can have; V returns early above it.
+	max     int
+	modules []vmoduleRule
+	// byPC caches the level of each call site of V.
+	byPC sync.Map
+}
+
+// vmoduleRule sets the verbosity of source files matching pattern.
+type vmoduleRule struct {
+	pattern string
+	level   int
+}
+
+// newVerbosity returns nil when cfg leaves verbosity at its default of 0.
+// Invalid vmodule entries are ignored, see Config.Validate.
+func newVerbosity(cfg *Config) *verbosity {
+	modules, _ := parseVModule(cfg.VModule)
+	if cfg.Verbosity <= 0 && len(modules) == 0 {
+		return nil
+	}
+
+	v := &verbosity{level: cfg.Verbosity, max: cfg.Verbosity, modules: modules}
+	for _, m := range modules {
+		if m.level > v.max {
+			v.max = m.level
+		}
+	}
+	return v
+}
+
+// forPC returns the verbosity of the source file containing pc.
+func (v *verbosity) forPC(pc uintptr) int {
+	if level, ok := v.byPC.Load(pc); ok {
+		return level.(int)
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	level := v.forFile(frame.File)
+	v.byPC.Store(pc, level)
+	return level
+}
+
+// forFile returns the level of the first vmodule pattern matching file.
+//
+// As in Google's glog, patterns are matched against the file name without
+// its .go extension ("gopher*"). Patterns containing a slash are matched
+// against the trailing path elements instead ("net/http/*").
+func (v *verbosity) forFile(file string) int {
+	file = strings.TrimSuffix(filepath.ToSlash(file), ".go")
+	base := file[strings.LastIndexByte(file, '/')+1:]
+	for _, m := range v.modules {
+		if !strings.Contains(m.pattern, "/") {
+			if ok, _ := filepath.Match(m.pattern, base); ok {
+				return m.level
+			}
+			continue
+		}
+		elems := strings.Count(m.pattern, "/") + 1
+		if ok, _ := filepath.Match(m.pattern, lastPathElems(file, elems)); ok {
+			return m.level
+		}
+	}
+	return v.level
+}
+
+// lastPathElems returns the last n slash-separated elements of path.
+func lastPathElems(path string, n int) string {
+	i := len(path)
+	for ; n > 0; n-- {
+		if i = strings.LastIndexByte(path[:i], '/'); i < 0 {
+			return path
+		}
+	}
+	return path[i+1:]
+}
+
+// parseVModule parses a comma-separated list of pattern=N entries, such as
+// "gopher*=3,net/http/*=2".
+func parseVModule(spec string) ([]vmoduleRule, error) {
+	var rules []vmoduleRule
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		pattern, levelStr, ok := strings.Cut(entry, "=")
+		pattern = strings.TrimSuffix(strings.TrimSpace(pattern), ".go")
+		if !ok || pattern == "" {
+			return nil, fmt.Errorf("invalid entry %q (want pattern=N)", entry)
+		}
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		level, err := strconv.Atoi(strings.TrimSpace(levelStr))
+		if err != nil || level < 0 {
+			return nil, fmt.Errorf("invalid level in %q (want a non-negative integer)", entry)
+		}
+		rules = append(rules, vmoduleRule{pattern: pattern, level: level})
+	}
+	return rules, nil
+}
@@ -0,0 +1,125 @@
+package glog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestVerbosityForFile(t *testing.T) {
+	modules, err := parseVModule("gopher*=3, net/http/*=2,server.go=1,/abs/only=4")
+	if err != nil {
+		t.Fatalf("parseVModule failed: %v", err)
+	}
+	v := &verbosity{level: 1, modules: modules}
+
+	tests := []struct {
+		file string
+		want int
+	}{
+		{"/src/app/gopher_test.go", 3},
+		{"/src/app/gophers.go", 3},
+		{"/go/src/net/http/client.go", 2},
+		{"/go/src/net/http2/client.go", 1},
+		{"/src/app/server.go", 1},
+		{"/abs/only.go", 4},
+		{"other.go", 1},
+	}
+	for _, tt := range tests {
+		if got := v.forFile(tt.file); got != tt.want {
+			t.Errorf("forFile(%q) = %d, want %d", tt.file, got, tt.want)
+		}
+	}
+}
+
+func TestParseVModuleErrors(t *testing.T) {
+	for _, spec := range []string{"foo", "foo=x", "foo=-1", "=2", "[=1"} {
+		if _, err := parseVModule(spec); err == nil {
+			t.Errorf("parseVModule(%q): expected an error, got nil", spec)
+		}
+	}
+	if rules, err := parseVModule(" , "); err != nil || len(rules) != 0 {
+		t.Errorf("Expected no rules for an empty spec, got %v, %v", rules, err)
+	}
+}
+
+func TestV(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "glog_test_verbosity")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	configPath := writeConfig(t, tempDir, baseConsoleConfig+`
+log_level: debug
+separate_levels: false
+verbosity: 1
+vmodule: "other_file=5,verbosity_test=2"
+`)
+	if err := Init(configPath, tempDir); err != nil {
+		t.Fatalf("Failed to initialize logger: %v", err)
+	}
+
+	V(1).Info("v1_enabled")
+	V(2).Infof("v2_enabled_by_%s", "vmodule")
+	V(3).Infow("v3_filtered")
+	if V(3) {
+		t.Error("V(3) should be disabled for this file")
+	}
+	Flush() //nolint:errcheck
+
+	content, err := os.ReadFile(filepath.Join(tempDir, "app.log"))
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	for _, want := range []string{"DEBUG", "v1_enabled", "v2_enabled_by_vmodule"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("Log file should contain %q. Content: %s", want, content)
+		}
+	}
+	if strings.Contains(string(content), "v3_filtered") {
+		t.Errorf("V(3) entry should be filtered. Content: %s", content)
+	}
+}
+
+func TestVRequiresDebug(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "glog_test_verbosity_info")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	if err := InitWithConfig(&Config{Directory: tempDir, LogLevel: "info", Verbosity: 3}); err != nil {
+		t.Fatalf("Failed to initialize logger: %v", err)
+	}
+	if V(0) || V(1) {
+		t.Error("V should be disabled while the debug level is disabled")
+	}
+}
+
+func TestVDisabledDoesNotAllocate(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "glog_test_verbosity_alloc")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	if err := InitWithConfig(&Config{Directory: tempDir, LogLevel: "debug", VModule: "verbosity_test=2,other_file=5"}); err != nil {
+		t.Fatalf("Failed to initialize logger: %v", err)
+	}
+	allocs := testing.AllocsPerRun(100, func() {
+		V(6).Info("disabled")
+		// Allowed by other_file but not by this file: resolved once, then cached.
+		V(3).Info("disabled")
+	})
+	if allocs != 0 {
+		t.Errorf("Expected 0 allocs for V calls that log nothing, got %v", allocs)
+	}
+}
+
+func BenchmarkVDisabled(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		V(3).Info("disabled")
+	}
+}
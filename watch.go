@@ -0,0 +1,154 @@
+package glog
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// DefaultWatchInterval is the polling interval used when Watch is given a
+// non-positive interval.
+const DefaultWatchInterval = 5 * time.Second
+
+// Watcher polls a ConfigSource and rebuilds the global logger when the
+// config changes.
+type Watcher struct {
+	source   ConfigSource
+	opts     []Option
+	interval time.Duration
+	onReload func(error)
+
+	lastErr string
+
+	// ctx is canceled by Stop to abort a Load in progress.
+	ctx      context.Context
+	cancel   context.CancelFunc
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// Watch initializes the global logger like Init and then polls cfgPath every
+// interval, rebuilding the global logger whenever the file content changes.
+//
+// The new logger is installed atomically; the files of the previous logger
+// are closed only after the new one is in place. If a reload fails the
+// previous logger keeps running. onReload is called after every reload
+// attempt with nil on success or the error on failure; when onReload is nil
+// the outcome is written to the global logger instead.
+//
+// 示例：
+//
+//	w, err := glog.Watch("./logger.yaml", "my-app", 10*time.Second, nil)
+//	if err != nil {
+//		log.Fatalf("failed to initialize logger: %v", err)
+//	}
+//	defer w.Stop()
+func Watch(cfgPath string, directory string, interval time.Duration, onReload func(error)) (*Watcher, error) {
+	return WatchSource(NewFileSource(cfgPath), interval, onReload, WithDirectory(directory))
+}
+
+// WatchSource initializes the global logger from the config loaded from
+// source and then polls source every interval, rebuilding the global logger
+// whenever Load returns a new config. opts are applied to every build on
+// top of the loaded config, as with Build(WithConfig(cfg), opts...); for an
+// EnvSource with a Prefix, WithEnvPrefix(Prefix) comes first, so the package
+// prefix does not override it. Reloads behave as described in Watch.
+//
+// 示例：
+//
+//	src := glog.NewHTTPSource("http://config.internal/services/order/logger.yaml")
+//	w, err := glog.WatchSource(src, 30*time.Second, nil, glog.WithDirectory("my-app"))
+func WatchSource(source ConfigSource, interval time.Duration, onReload func(error), opts ...Option) (*Watcher, error) {
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+
+	// The builds of an EnvSource read the same variables as the source.
+	if env, ok := source.(*EnvSource); ok && env.Prefix != "" {
+		opts = append([]Option{WithEnvPrefix(env.Prefix)}, opts...)
+	}
+
+	w := &Watcher{
+		source:   source,
+		opts:     opts,
+		interval: interval,
+		onReload: onReload,
+		done:     make(chan struct{}),
+	}
+	w.ctx, w.cancel = context.WithCancel(context.Background())
+
+	cfg, err := source.Load(w.ctx)
+	if err == nil {
+		err = w.build(cfg)
+	}
+	if err != nil {
+		w.cancel()
+		return nil, err
+	}
+
+	go w.run()
+	return w, nil
+}
+
+// Stop stops polling. The current global logger stays in place.
+func (w *Watcher) Stop() {
+	w.stopOnce.Do(w.cancel)
+	<-w.done
+}
+
+func (w *Watcher) run() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.ctx.Done():
+			return
+		case <-ticker.C:
+			w.poll()
+		}
+	}
+}
+
+// poll reloads the logger if the config changed. A load error is reported
+// once until it changes, so a missing file or an unreachable server does
+// not flood the callback on every tick.
+func (w *Watcher) poll() {
+	cfg, err := w.source.Load(w.ctx)
+	if errors.Is(err, ErrNotModified) || w.ctx.Err() != nil {
+		return
+	}
+	if err != nil {
+		if err.Error() != w.lastErr {
+			w.lastErr = err.Error()
+			w.report(err)
+		}
+		return
+	}
+	w.lastErr = ""
+
+	w.report(w.build(cfg))
+}
+
+// build installs a global logger for cfg; the writers of the previous one
+// are closed once it is in place.
+func (w *Watcher) build(cfg *Config) error {
+	opts := append([]Option{WithConfig(cfg)}, w.opts...)
+	_, err := Build(append(opts, AsGlobal())...)
+	return err
+}
+
+func (w *Watcher) report(err error) {
+	if w.onReload != nil {
+		w.onReload(err)
+		return
+	}
+	if err != nil {
+		Errorf("failed to reload logger config from %v: %v", w.source, err)
+	} else {
+		Infof("reloaded logger config from %v", w.source)
+	}
+}
@@ -0,0 +1,181 @@
+package glog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// waitReload waits for the next reload result reported by a Watcher.
+func waitReload(t *testing.T, results <-chan error) error {
+	t.Helper()
+	select {
+	case err := <-results:
+		return err
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for config reload")
+		return nil
+	}
+}
+
+func TestWatchReloadsOnChange(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "glog_test_watch")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	configPath := writeConfig(t, tempDir, baseConsoleConfig+"log_level: info\n")
+
+	results := make(chan error, 10)
+	w, err := Watch(configPath, tempDir, 10*time.Millisecond, func(err error) { results <- err })
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+	defer w.Stop()
+
+	Debug("watch_debug_before_reload")
+
+	writeConfig(t, tempDir, baseConsoleConfig+"log_level: debug\n")
+	if err := waitReload(t, results); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+
+	Debug("watch_debug_after_reload")
+	Flush() //nolint:errcheck
+
+	content, err := os.ReadFile(filepath.Join(tempDir, FileDebug))
+	if err != nil {
+		t.Fatalf("Failed to read debug log: %v", err)
+	}
+	if strings.Contains(string(content), "watch_debug_before_reload") {
+		t.Errorf("Debug message before reload should be filtered. Content: %s", content)
+	}
+	if !strings.Contains(string(content), "watch_debug_after_reload") {
+		t.Errorf("Debug message after reload should be written. Content: %s", content)
+	}
+}
+
+func TestWatchReloadsOnIncludedFileChange(t *testing.T) {
+	tempDir := t.TempDir()
+	basePath := filepath.Join(tempDir, "base.yaml")
+	if err := os.WriteFile(basePath, []byte(baseConsoleConfig+"log_level: info\n"), 0644); err != nil {
+		t.Fatalf("Failed to write base config: %v", err)
+	}
+	configPath := writeConfig(t, tempDir, "include: base.yaml\n")
+
+	results := make(chan error, 10)
+	w, err := Watch(configPath, tempDir, 10*time.Millisecond, func(err error) { results <- err })
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+	defer w.Stop()
+
+	if err := os.WriteFile(basePath, []byte(baseConsoleConfig+"log_level: debug\n"), 0644); err != nil {
+		t.Fatalf("Failed to write base config: %v", err)
+	}
+	if err := waitReload(t, results); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if GetLevel() != zapcore.DebugLevel {
+		t.Errorf("Expected the level of the edited base file, got %s", GetLevel())
+	}
+	SetLevel(zapcore.InfoLevel)
+}
+
+func TestWatchKeepsLoggerOnInvalidConfig(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "glog_test_watch_invalid")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	configPath := writeConfig(t, tempDir, baseConsoleConfig)
+
+	results := make(chan error, 10)
+	w, err := Watch(configPath, tempDir, 10*time.Millisecond, func(err error) { results <- err })
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+	defer w.Stop()
+
+	stateBefore := getState()
+
+	writeConfig(t, tempDir, "{{invalid yaml")
+	if err := waitReload(t, results); err == nil {
+		t.Fatal("Expected reload error for invalid config, got nil")
+	}
+	if getState() != stateBefore {
+		t.Error("A failed reload should NOT change the global logger, but it did")
+	}
+
+	Info("watch_still_logging")
+	checkLogFile(t, filepath.Join(tempDir, FileInfo), "INFO", "watch_still_logging")
+}
+
+func TestWatchMissingFile(t *testing.T) {
+	if _, err := Watch("non_existent_config.yaml", "somedir", time.Second, nil); err == nil {
+		t.Error("Expected an error when watching a non-existent config file, got nil")
+	}
+}
+
+func TestInitClosesPreviousWriters(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "glog_test_reload_close")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	var replaced bool
+	currentState.Store(&loggerState{
+		logger: getState().logger,
+		writers: closerFunc(func() error {
+			replaced = getState().config != nil && getState().config.Directory == tempDir
+			return nil
+		}),
+	})
+
+	if err := InitWithConfig(&Config{Directory: tempDir}); err != nil {
+		t.Fatalf("InitWithConfig failed: %v", err)
+	}
+	if !replaced {
+		t.Error("Previous writers should be closed after the new logger is installed")
+	}
+}
+
+func TestGlobalHandlesFollowInit(t *testing.T) {
+	dirA, dirB := t.TempDir(), t.TempDir()
+	handle, err := NewFromConfig(&Config{Directory: dirA, SeparateLevels: false}, true)
+	if err != nil {
+		t.Fatalf("NewFromConfig failed: %v", err)
+	}
+	built, err := Build(WithConfig(&Config{Directory: dirA, SeparateLevels: false}), AsGlobal())
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if err := InitWithConfig(&Config{Directory: dirB, SeparateLevels: false}); err != nil {
+		t.Fatalf("InitWithConfig failed: %v", err)
+	}
+	handle.Info("handle_after_init")
+	built.Info("built_after_init")
+	Flush() //nolint:errcheck
+
+	a, _ := os.ReadFile(filepath.Join(dirA, "app.log"))
+	b, _ := os.ReadFile(filepath.Join(dirB, "app.log"))
+	if strings.Contains(string(a), "_after_init") {
+		t.Errorf("Handles should not reopen the files of a replaced logger. Content: %s", a)
+	}
+	for _, want := range []string{"handle_after_init", "built_after_init"} {
+		if !strings.Contains(string(b), want) {
+			t.Errorf("Handles should write to the current global logger, missing %q. Content: %s", want, b)
+		}
+	}
+}
+
+type closerFunc func() error
+
+func (f closerFunc) Close() error { return f() }